@@ -0,0 +1,533 @@
+package heleket
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/bits"
+	"regexp"
+	"strings"
+)
+
+var (
+	ErrInvalidAddress = errors.New("heleket: invalid address")
+	ErrInvalidMemo    = errors.New("heleket: invalid memo")
+)
+
+// ValidateAddress checks address against the format of network as
+// registered in DefaultRegistry. Addresses on networks the registry does
+// not know are accepted.
+func ValidateAddress(network, address string) error {
+	return DefaultRegistry.ValidateAddress(network, address)
+}
+
+// ValidateAddress checks address against the format of network. Addresses
+// on networks the registry does not know, or that have no validator, are
+// accepted.
+func (r *Registry) ValidateAddress(network, address string) error {
+	if address == "" {
+		return fmt.Errorf("%w: address is empty", ErrInvalidAddress)
+	}
+	info, ok := r.Network(network)
+	if !ok || info.ValidateAddress == nil {
+		return nil
+	}
+	if err := info.ValidateAddress(address); err != nil {
+		return fmt.Errorf("%w (network %s, address %s)", err, info.Code, maskAddress(address))
+	}
+	return nil
+}
+
+// ValidateMemo checks memo against the memo requirement of network.
+func (r *Registry) ValidateMemo(network, memo string) error {
+	info, ok := r.Network(network)
+	if !ok {
+		return nil
+	}
+	switch {
+	case info.Memo == MemoRequired && memo == "":
+		return fmt.Errorf("%w: network %s requires a memo", ErrInvalidMemo, info.Code)
+	case info.Memo == MemoNone && memo != "":
+		return fmt.Errorf("%w: network %s does not support memos", ErrInvalidMemo, info.Code)
+	}
+	return nil
+}
+
+// ValidateAddressAny checks that address is accepted by the validator of
+// at least one network in the registry. Networks without a validator are
+// skipped. It is used when the target network is not known.
+func (r *Registry) ValidateAddressAny(address string) error {
+	if address == "" {
+		return fmt.Errorf("%w: address is empty", ErrInvalidAddress)
+	}
+	for _, info := range r.Networks() {
+		if info.ValidateAddress != nil && info.ValidateAddress(address) == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s is not valid on any known network", ErrInvalidAddress, maskAddress(address))
+}
+
+// WithoutAddressValidation disables the address and memo checks made
+// before CreatePayout, Refund and BlockedAddressRefund.
+func WithoutAddressValidation() Option {
+	return func(c *Heleket) {
+		c.skipAddressCheck = true
+	}
+}
+
+// checkAddress validates the destination of a transfer on network before
+// the request is sent.
+func (c *Heleket) checkAddress(network, address, memo string) error {
+	if c.skipAddressCheck {
+		return nil
+	}
+	if err := c.registry.ValidateAddress(network, address); err != nil {
+		return err
+	}
+	return c.registry.ValidateMemo(network, memo)
+}
+
+var (
+	errChecksum = fmt.Errorf("%w: checksum mismatch", ErrInvalidAddress)
+	errFormat   = fmt.Errorf("%w: malformed", ErrInvalidAddress)
+	errVersion  = fmt.Errorf("%w: unexpected version byte", ErrInvalidAddress)
+	errLength   = fmt.Errorf("%w: unexpected length", ErrInvalidAddress)
+)
+
+// base58Check returns a validator for Base58Check addresses with one of
+// the given version bytes and a 20-byte payload.
+func base58Check(versions ...byte) AddressValidator {
+	return func(address string) error {
+		decoded, err := decodeBase58Check(address)
+		if err != nil {
+			return err
+		}
+		if len(decoded) != 21 {
+			return errLength
+		}
+		if bytes.IndexByte(versions, decoded[0]) < 0 {
+			return errVersion
+		}
+		return nil
+	}
+}
+
+func validateTronAddress(address string) error {
+	return base58Check(0x41)(address)
+}
+
+func validateBitcoinAddress(address string) error {
+	if hasPrefixFold(address, "bc1") {
+		return validateSegwit("bc", address)
+	}
+	return base58Check(0x00, 0x05)(address)
+}
+
+func validateLitecoinAddress(address string) error {
+	if hasPrefixFold(address, "ltc1") {
+		return validateSegwit("ltc", address)
+	}
+	return base58Check(0x30, 0x32, 0x05)(address)
+}
+
+func validateDogecoinAddress(address string) error {
+	return base58Check(0x1e, 0x16)(address)
+}
+
+func validateDashAddress(address string) error {
+	return base58Check(0x4c, 0x10)(address)
+}
+
+func validateBitcoinCashAddress(address string) error {
+	if strings.HasPrefix(address, "1") || strings.HasPrefix(address, "3") {
+		return base58Check(0x00, 0x05)(address)
+	}
+	return validateCashAddr(address)
+}
+
+func validateSolanaAddress(address string) error {
+	decoded, err := decodeBase58(address)
+	if err != nil {
+		return err
+	}
+	if len(decoded) != 32 {
+		return errLength
+	}
+	return nil
+}
+
+var moneroAddressPattern = regexp.MustCompile(`^[48][1-9A-HJ-NP-Za-km-z]{94}([1-9A-HJ-NP-Za-km-z]{11})?$`)
+
+// validateMoneroAddress checks the shape of standard, subaddress and
+// integrated Monero addresses. The Keccak checksum uses Monero's own
+// block-wise Base58 and is not verified.
+func validateMoneroAddress(address string) error {
+	if !moneroAddressPattern.MatchString(address) {
+		return errFormat
+	}
+	return nil
+}
+
+// validateEVMAddress accepts 0x-prefixed hex addresses. Mixed-case
+// addresses must carry a valid EIP-55 checksum.
+func validateEVMAddress(address string) error {
+	if len(address) != 42 || !strings.HasPrefix(address, "0x") {
+		return errFormat
+	}
+	hexPart := address[2:]
+	if _, err := hex.DecodeString(hexPart); err != nil {
+		return errFormat
+	}
+	lower := strings.ToLower(hexPart)
+	if hexPart == lower || hexPart == strings.ToUpper(hexPart) {
+		return nil
+	}
+	if hexPart != eip55(lower) {
+		return errChecksum
+	}
+	return nil
+}
+
+func eip55(lowerHex string) string {
+	hash := keccak256([]byte(lowerHex))
+	out := []byte(lowerHex)
+	for i, c := range out {
+		if c < 'a' {
+			continue
+		}
+		nibble := hash[i/2]
+		if i%2 == 0 {
+			nibble >>= 4
+		}
+		if nibble&0x0f >= 8 {
+			out[i] = c - 'a' + 'A'
+		}
+	}
+	return string(out)
+}
+
+var tonRawAddressPattern = regexp.MustCompile(`^-?[0-9]+:[0-9a-fA-F]{64}$`)
+
+// validateTONAddress accepts raw "workchain:hex" addresses and 48-character
+// user-friendly addresses in either base64 alphabet, verifying their CRC16.
+func validateTONAddress(address string) error {
+	if tonRawAddressPattern.MatchString(address) {
+		return nil
+	}
+	if len(address) != 48 {
+		return errLength
+	}
+	encoding := base64.RawURLEncoding
+	if strings.ContainsAny(address, "+/") {
+		encoding = base64.RawStdEncoding
+	}
+	decoded, err := encoding.DecodeString(address)
+	if err != nil || len(decoded) != 36 {
+		return errFormat
+	}
+	if tag := decoded[0] &^ 0x80; tag != 0x11 && tag != 0x51 {
+		return errVersion
+	}
+	if crc16XModem(decoded[:34]) != binary.BigEndian.Uint16(decoded[34:]) {
+		return errChecksum
+	}
+	return nil
+}
+
+func hasPrefixFold(s, prefix string) bool {
+	return len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix)
+}
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+var base58Index = func() [256]int8 {
+	var index [256]int8
+	for i := range index {
+		index[i] = -1
+	}
+	for i := 0; i < len(base58Alphabet); i++ {
+		index[base58Alphabet[i]] = int8(i)
+	}
+	return index
+}()
+
+func decodeBase58(s string) ([]byte, error) {
+	if s == "" {
+		return nil, errFormat
+	}
+	zeros := 0
+	for zeros < len(s) && s[zeros] == '1' {
+		zeros++
+	}
+	// Little-endian base-256 digits of the value.
+	var out []byte
+	for i := 0; i < len(s); i++ {
+		carry := int(base58Index[s[i]])
+		if carry < 0 {
+			return nil, errFormat
+		}
+		for j := range out {
+			carry += int(out[j]) * 58
+			out[j] = byte(carry)
+			carry >>= 8
+		}
+		for carry > 0 {
+			out = append(out, byte(carry))
+			carry >>= 8
+		}
+	}
+	decoded := make([]byte, zeros, zeros+len(out))
+	for i := len(out) - 1; i >= 0; i-- {
+		decoded = append(decoded, out[i])
+	}
+	return decoded, nil
+}
+
+// decodeBase58Check decodes s and verifies its double-SHA256 checksum,
+// returning the version byte and payload.
+func decodeBase58Check(s string) ([]byte, error) {
+	decoded, err := decodeBase58(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(decoded) < 5 {
+		return nil, errLength
+	}
+	payload, checksum := decoded[:len(decoded)-4], decoded[len(decoded)-4:]
+	first := sha256.Sum256(payload)
+	second := sha256.Sum256(first[:])
+	if !bytes.Equal(second[:4], checksum) {
+		return nil, errChecksum
+	}
+	return payload, nil
+}
+
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+const (
+	bech32Const  = 1
+	bech32mConst = 0x2bc830a3
+)
+
+func bech32Polymod(values []byte) uint32 {
+	generator := [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (top>>i)&1 == 1 {
+				chk ^= generator[i]
+			}
+		}
+	}
+	return chk
+}
+
+// validateSegwit checks a bech32 (witness v0) or bech32m (v1+) address
+// with the given human-readable part.
+func validateSegwit(hrp, address string) error {
+	if address != strings.ToLower(address) && address != strings.ToUpper(address) {
+		return errFormat
+	}
+	address = strings.ToLower(address)
+	sep := strings.LastIndexByte(address, '1')
+	if sep != len(hrp) || address[:sep] != hrp || len(address)-sep-1 < 7 || len(address) > 90 {
+		return errFormat
+	}
+
+	data := make([]byte, 0, len(address)-sep-1)
+	for _, c := range address[sep+1:] {
+		i := strings.IndexRune(bech32Charset, c)
+		if i < 0 {
+			return errFormat
+		}
+		data = append(data, byte(i))
+	}
+
+	expanded := make([]byte, 0, 2*len(hrp)+1+len(data))
+	for i := 0; i < len(hrp); i++ {
+		expanded = append(expanded, hrp[i]>>5)
+	}
+	expanded = append(expanded, 0)
+	for i := 0; i < len(hrp); i++ {
+		expanded = append(expanded, hrp[i]&31)
+	}
+	expanded = append(expanded, data...)
+
+	version := data[0]
+	want := uint32(bech32Const)
+	if version > 0 {
+		want = bech32mConst
+	}
+	if version > 16 {
+		return errVersion
+	}
+	if bech32Polymod(expanded) != want {
+		return errChecksum
+	}
+
+	program, ok := convertBits(data[1:len(data)-6], 5, 8)
+	if !ok || len(program) < 2 || len(program) > 40 {
+		return errLength
+	}
+	if version == 0 && len(program) != 20 && len(program) != 32 {
+		return errLength
+	}
+	return nil
+}
+
+func convertBits(data []byte, from, to uint) ([]byte, bool) {
+	var acc, nbits uint
+	out := make([]byte, 0, len(data)*int(from)/int(to))
+	for _, v := range data {
+		acc = acc<<from | uint(v)
+		nbits += from
+		for nbits >= to {
+			nbits -= to
+			out = append(out, byte(acc>>nbits)&(1<<to-1))
+		}
+	}
+	if nbits >= from || (acc<<(to-nbits))&(1<<to-1) != 0 {
+		return nil, false
+	}
+	return out, true
+}
+
+// validateCashAddr checks a Bitcoin Cash CashAddr address, with or
+// without the "bitcoincash:" prefix.
+func validateCashAddr(address string) error {
+	if address != strings.ToLower(address) && address != strings.ToUpper(address) {
+		return errFormat
+	}
+	address = strings.ToLower(address)
+	payload := strings.TrimPrefix(address, "bitcoincash:")
+	if len(payload) != 42 || (payload[0] != 'q' && payload[0] != 'p') {
+		return errFormat
+	}
+
+	values := make([]byte, 0, len("bitcoincash")+1+len(payload))
+	for i := 0; i < len("bitcoincash"); i++ {
+		values = append(values, "bitcoincash"[i]&31)
+	}
+	values = append(values, 0)
+	for _, c := range payload {
+		i := strings.IndexRune(bech32Charset, c)
+		if i < 0 {
+			return errFormat
+		}
+		values = append(values, byte(i))
+	}
+	if cashAddrPolymod(values) != 0 {
+		return errChecksum
+	}
+	return nil
+}
+
+func cashAddrPolymod(values []byte) uint64 {
+	generator := [5]uint64{0x98f2bc8e61, 0x79b76d99e2, 0xf33e5fb3c4, 0xae2eabe2a8, 0x1e4f43e470}
+	c := uint64(1)
+	for _, d := range values {
+		c0 := byte(c >> 35)
+		c = (c&0x07ffffffff)<<5 ^ uint64(d)
+		for i := 0; i < 5; i++ {
+			if (c0>>i)&1 == 1 {
+				c ^= generator[i]
+			}
+		}
+	}
+	return c ^ 1
+}
+
+func crc16XModem(data []byte) uint16 {
+	var crc uint16
+	for _, b := range data {
+		crc ^= uint16(b) << 8
+		for i := 0; i < 8; i++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+var keccakRoundConstants = [24]uint64{
+	0x0000000000000001, 0x0000000000008082, 0x800000000000808a, 0x8000000080008000,
+	0x000000000000808b, 0x0000000080000001, 0x8000000080008081, 0x8000000000008009,
+	0x000000000000008a, 0x0000000000000088, 0x0000000080008009, 0x000000008000000a,
+	0x000000008000808b, 0x800000000000008b, 0x8000000000008089, 0x8000000000008003,
+	0x8000000000008002, 0x8000000000000080, 0x000000000000800a, 0x800000008000000a,
+	0x8000000080008081, 0x8000000000008080, 0x0000000080000001, 0x8000000080008008,
+}
+
+var (
+	keccakRotations = [24]int{1, 3, 6, 10, 15, 21, 28, 36, 45, 55, 2, 14, 27, 41, 56, 8, 25, 43, 62, 18, 39, 61, 20, 44}
+	keccakLanes     = [24]int{10, 7, 11, 17, 18, 3, 5, 16, 8, 21, 24, 4, 15, 23, 19, 13, 12, 2, 20, 14, 22, 9, 6, 1}
+)
+
+func keccakF1600(a *[25]uint64) {
+	var c [5]uint64
+	for round := 0; round < 24; round++ {
+		for i := 0; i < 5; i++ {
+			c[i] = a[i] ^ a[i+5] ^ a[i+10] ^ a[i+15] ^ a[i+20]
+		}
+		for i := 0; i < 5; i++ {
+			d := c[(i+4)%5] ^ bits.RotateLeft64(c[(i+1)%5], 1)
+			for j := 0; j < 25; j += 5 {
+				a[j+i] ^= d
+			}
+		}
+
+		t := a[1]
+		for i := 0; i < 24; i++ {
+			j := keccakLanes[i]
+			t, a[j] = a[j], bits.RotateLeft64(t, keccakRotations[i])
+		}
+
+		for j := 0; j < 25; j += 5 {
+			copy(c[:], a[j:j+5])
+			for i := 0; i < 5; i++ {
+				a[j+i] ^= ^c[(i+1)%5] & c[(i+2)%5]
+			}
+		}
+
+		a[0] ^= keccakRoundConstants[round]
+	}
+}
+
+// keccak256 is the original Keccak-256 used by Ethereum, which differs
+// from SHA3-256 in its padding byte.
+func keccak256(data []byte) [32]byte {
+	const rate = 136
+	var state [25]uint64
+	absorb := func(block []byte) {
+		for i := 0; i < rate/8; i++ {
+			state[i] ^= binary.LittleEndian.Uint64(block[i*8:])
+		}
+		keccakF1600(&state)
+	}
+
+	for len(data) >= rate {
+		absorb(data[:rate])
+		data = data[rate:]
+	}
+	var last [rate]byte
+	copy(last[:], data)
+	last[len(data)] ^= 0x01
+	last[rate-1] ^= 0x80
+	absorb(last[:])
+
+	var out [32]byte
+	for i := 0; i < 4; i++ {
+		binary.LittleEndian.PutUint64(out[i*8:], state[i])
+	}
+	return out
+}
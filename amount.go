@@ -0,0 +1,364 @@
+package heleket
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Amount is an exact decimal amount as Heleket sends it, e.g. "3.00000000".
+//
+// Amount keeps the number of fractional digits it was parsed with, so it
+// renders and marshals back exactly as received. The zero value is a null
+// amount, which marshals to JSON null. Amounts are immutable; arithmetic
+// returns new values.
+type Amount struct {
+	coef  *big.Int // value = coef * 10^-scale; nil for a null amount
+	scale int32
+}
+
+// RoundingMode selects how Round and Div discard digits.
+type RoundingMode int
+
+const (
+	// RoundHalfUp rounds to nearest, ties away from zero.
+	RoundHalfUp RoundingMode = iota
+	// RoundHalfEven rounds to nearest, ties to the even neighbour.
+	RoundHalfEven
+	// RoundDown rounds toward zero (truncates).
+	RoundDown
+	// RoundUp rounds away from zero.
+	RoundUp
+	// RoundFloor rounds toward negative infinity.
+	RoundFloor
+	// RoundCeiling rounds toward positive infinity.
+	RoundCeiling
+)
+
+var errInvalidAmount = errors.New("heleket: invalid amount")
+
+// ParseAmount parses a decimal string such as "10", "-0.5" or "3.00000000".
+// An empty string yields a null amount.
+func ParseAmount(s string) (Amount, error) {
+	if s == "" {
+		return Amount{}, nil
+	}
+
+	digits := s
+	negative := false
+	switch digits[0] {
+	case '-':
+		negative = true
+		digits = digits[1:]
+	case '+':
+		digits = digits[1:]
+	}
+
+	intPart, fracPart, _ := strings.Cut(digits, ".")
+	if (intPart == "" && fracPart == "") || !isDigits(intPart) || !isDigits(fracPart) {
+		return Amount{}, fmt.Errorf("%w: %q", errInvalidAmount, s)
+	}
+
+	coef, ok := new(big.Int).SetString(intPart+fracPart, 10)
+	if !ok {
+		return Amount{}, fmt.Errorf("%w: %q", errInvalidAmount, s)
+	}
+	if negative {
+		coef.Neg(coef)
+	}
+	return Amount{coef: coef, scale: int32(len(fracPart))}, nil
+}
+
+// MustParseAmount is like ParseAmount but panics on malformed input. It is
+// intended for constants in code and tests.
+func MustParseAmount(s string) Amount {
+	a, err := ParseAmount(s)
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
+
+// NewAmount returns unscaled * 10^-scale, e.g. NewAmount(150, 2) is 1.50
+// and NewAmount(5, -2) is 500.
+func NewAmount(unscaled int64, scale int32) Amount {
+	return Amount{coef: big.NewInt(unscaled), scale: scale}
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// IsNull reports whether the amount is null, i.e. absent in the response.
+func (a Amount) IsNull() bool {
+	return a.coef == nil
+}
+
+// IsZero reports whether the amount is zero. A null amount is zero.
+func (a Amount) IsZero() bool {
+	return a.coef == nil || a.coef.Sign() == 0
+}
+
+// Sign returns -1, 0 or +1. A null amount has sign 0.
+func (a Amount) Sign() int {
+	if a.coef == nil {
+		return 0
+	}
+	return a.coef.Sign()
+}
+
+// Scale returns the number of fractional digits.
+func (a Amount) Scale() int32 {
+	return a.scale
+}
+
+// String renders the amount with its own number of fractional digits.
+// A null amount renders as the empty string.
+func (a Amount) String() string {
+	if a.coef == nil {
+		return ""
+	}
+
+	digits := new(big.Int).Abs(a.coef).String()
+	if a.scale > 0 {
+		if pad := int(a.scale) + 1 - len(digits); pad > 0 {
+			digits = strings.Repeat("0", pad) + digits
+		}
+		digits = digits[:len(digits)-int(a.scale)] + "." + digits[len(digits)-int(a.scale):]
+	} else if a.scale < 0 {
+		digits += strings.Repeat("0", int(-a.scale))
+	}
+
+	if a.coef.Sign() < 0 {
+		return "-" + digits
+	}
+	return digits
+}
+
+// StringFixed renders the amount rounded half-up to scale fractional digits.
+func (a Amount) StringFixed(scale int32) string {
+	return a.Round(scale, RoundHalfUp).String()
+}
+
+// StringFor renders the amount at the precision of currency.
+func (a Amount) StringFor(currency string) string {
+	return a.RoundFor(currency, RoundHalfUp).String()
+}
+
+// Rat returns the amount as a big.Rat, or nil for a null amount.
+func (a Amount) Rat() *big.Rat {
+	if a.coef == nil {
+		return nil
+	}
+	return new(big.Rat).Mul(new(big.Rat).SetInt(a.coef), ratPow10(-a.scale))
+}
+
+// Float64 returns the nearest float64. It is meant for display and metrics,
+// not for arithmetic.
+func (a Amount) Float64() float64 {
+	if a.coef == nil {
+		return 0
+	}
+	f, _ := a.Rat().Float64()
+	return f
+}
+
+func (a Amount) MarshalJSON() ([]byte, error) {
+	if a.coef == nil {
+		return []byte("null"), nil
+	}
+	return json.Marshal(a.String())
+}
+
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		*a = Amount{}
+		return nil
+	}
+
+	s := string(data)
+	if len(data) > 0 && data[0] == '"' {
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+	}
+
+	parsed, err := ParseAmount(s)
+	if err != nil {
+		return err
+	}
+	*a = parsed
+	return nil
+}
+
+// orZero treats a null amount as zero.
+func (a Amount) orZero() Amount {
+	if a.coef == nil {
+		return Amount{coef: new(big.Int)}
+	}
+	return a
+}
+
+// rescale returns the coefficient of a at a larger scale.
+func (a Amount) rescale(scale int32) *big.Int {
+	coef := new(big.Int).Set(a.coef)
+	if scale > a.scale {
+		coef.Mul(coef, pow10(scale-a.scale))
+	}
+	return coef
+}
+
+// Add returns a + b. A null operand counts as zero.
+func (a Amount) Add(b Amount) Amount {
+	a, b = a.orZero(), b.orZero()
+	scale := max(a.scale, b.scale)
+	return Amount{coef: new(big.Int).Add(a.rescale(scale), b.rescale(scale)), scale: scale}
+}
+
+// Sub returns a - b. A null operand counts as zero.
+func (a Amount) Sub(b Amount) Amount {
+	return a.Add(b.Neg())
+}
+
+// Mul returns the exact product a * b. A null operand counts as zero.
+func (a Amount) Mul(b Amount) Amount {
+	a, b = a.orZero(), b.orZero()
+	return Amount{coef: new(big.Int).Mul(a.coef, b.coef), scale: a.scale + b.scale}
+}
+
+// Div returns a / b rounded to scale fractional digits. It panics if b is zero.
+func (a Amount) Div(b Amount, scale int32, mode RoundingMode) Amount {
+	b = b.orZero()
+	if b.coef.Sign() == 0 {
+		panic("heleket: amount division by zero")
+	}
+	return roundRat(new(big.Rat).Quo(a.orZero().Rat(), b.Rat()), scale, mode)
+}
+
+// Neg returns -a.
+func (a Amount) Neg() Amount {
+	a = a.orZero()
+	return Amount{coef: new(big.Int).Neg(a.coef), scale: a.scale}
+}
+
+// Abs returns |a|.
+func (a Amount) Abs() Amount {
+	a = a.orZero()
+	return Amount{coef: new(big.Int).Abs(a.coef), scale: a.scale}
+}
+
+// Cmp compares a and b numerically, returning -1, 0 or +1. Trailing zeros do
+// not matter: "3.00000000" equals "3". A null amount counts as zero.
+func (a Amount) Cmp(b Amount) int {
+	a, b = a.orZero(), b.orZero()
+	scale := max(a.scale, b.scale)
+	return a.rescale(scale).Cmp(b.rescale(scale))
+}
+
+// Equal reports whether a and b are numerically equal.
+func (a Amount) Equal(b Amount) bool {
+	return a.Cmp(b) == 0
+}
+
+// LessThan reports whether a < b.
+func (a Amount) LessThan(b Amount) bool {
+	return a.Cmp(b) < 0
+}
+
+// GreaterThan reports whether a > b.
+func (a Amount) GreaterThan(b Amount) bool {
+	return a.Cmp(b) > 0
+}
+
+// Round returns a with exactly scale fractional digits, rounding with mode
+// if digits have to be dropped. A negative scale rounds to a multiple of
+// 10^-scale, e.g. Round(-2, ...) rounds to hundreds. Rounding a null amount
+// yields null.
+func (a Amount) Round(scale int32, mode RoundingMode) Amount {
+	if a.coef == nil {
+		return a
+	}
+	if scale >= a.scale {
+		return Amount{coef: a.rescale(scale), scale: scale}
+	}
+	return roundRat(a.Rat(), scale, mode)
+}
+
+// RoundFor rounds a to the precision of currency, see CurrencyPrecision.
+func (a Amount) RoundFor(currency string, mode RoundingMode) Amount {
+	return a.Round(CurrencyPrecision(currency), mode)
+}
+
+// roundRat rounds r to scale fractional digits.
+func roundRat(r *big.Rat, scale int32, mode RoundingMode) Amount {
+	scaled := new(big.Rat).Mul(r, ratPow10(scale))
+	num, den := scaled.Num(), scaled.Denom()
+
+	quo, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+	if rem.Sign() != 0 {
+		sign := num.Sign()
+		away := false
+		switch mode {
+		case RoundDown:
+		case RoundUp:
+			away = true
+		case RoundFloor:
+			away = sign < 0
+		case RoundCeiling:
+			away = sign > 0
+		case RoundHalfUp, RoundHalfEven:
+			twice := new(big.Int).Abs(rem)
+			twice.Lsh(twice, 1)
+			switch c := twice.Cmp(den); {
+			case c > 0:
+				away = true
+			case c == 0:
+				away = mode == RoundHalfUp || quo.Bit(0) == 1
+			}
+		}
+		if away {
+			quo.Add(quo, big.NewInt(int64(sign)))
+		}
+	}
+	return Amount{coef: quo, scale: scale}
+}
+
+// pow10 returns 10^n for n >= 0.
+func pow10(n int32) *big.Int {
+	if n <= 0 {
+		return big.NewInt(1)
+	}
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+// ratPow10 returns 10^n for any n.
+func ratPow10(n int32) *big.Rat {
+	if n < 0 {
+		return new(big.Rat).SetFrac(big.NewInt(1), pow10(-n))
+	}
+	return new(big.Rat).SetInt(pow10(n))
+}
+
+// defaultPrecision is the number of fractional digits Heleket uses for
+// crypto amounts.
+const defaultPrecision = 8
+
+// CurrencyPrecision returns the number of fractional digits amounts in
+// currency are rendered with, as recorded in DefaultRegistry: 2 for fiat
+// currencies, the token precision for stablecoins and TRX, and 8 for
+// other and unknown crypto currencies.
+func CurrencyPrecision(currency string) int32 {
+	if info, ok := DefaultRegistry.Currency(currency); ok {
+		return info.Precision
+	}
+	return defaultPrecision
+}
@@ -0,0 +1,59 @@
+package heleket
+
+// Typed accessors for the decimal string fields of API types. Each returns
+// the field parsed as an Amount, or a null Amount if the field is empty or
+// malformed.
+
+func amountOf(s string) Amount {
+	a, err := ParseAmount(s)
+	if err != nil {
+		return Amount{}
+	}
+	return a
+}
+
+func (p *Payment) AmountValue() Amount                  { return amountOf(p.Amount) }
+func (p *Payment) PaymentAmountValue() Amount           { return amountOf(p.PaymentAmount) }
+func (p *Payment) PaymentAmountUSDValue() Amount        { return amountOf(p.PaymentAmountUSD) }
+func (p *Payment) PayerAmountValue() Amount             { return amountOf(p.PayerAmount) }
+func (p *Payment) PayerAmountExchangeRateValue() Amount { return amountOf(p.PayerAmountExchangeRate) }
+func (p *Payment) DiscountValue() Amount                { return amountOf(p.Discount) }
+func (p *Payment) MerchantAmountValue() Amount          { return amountOf(p.MerchantAmount) }
+func (p *Payment) CommissionValue() Amount              { return amountOf(p.Commission) }
+
+func (c *PaymentConvert) CommissionValue() Amount { return amountOf(c.Commission) }
+func (c *PaymentConvert) RateValue() Amount       { return amountOf(c.Rate) }
+func (c *PaymentConvert) AmountValue() Amount     { return amountOf(c.Amount) }
+
+func (l *PaymentServiceLimit) MinAmountValue() Amount { return amountOf(l.MinAmount) }
+func (l *PaymentServiceLimit) MaxAmountValue() Amount { return amountOf(l.MaxAmount) }
+
+func (c *PaymentServiceCommission) FeeAmountValue() Amount { return amountOf(c.FeeAmount) }
+func (c *PaymentServiceCommission) PercentValue() Amount   { return amountOf(c.Percent) }
+
+func (p *Payout) AmountValue() Amount      { return amountOf(p.Amount) }
+func (p *Payout) BalanceValue() Amount     { return amountOf(p.Balance) }
+func (p *Payout) PayerAmountValue() Amount { return amountOf(p.PayerAmount) }
+
+func (l *PayoutServiceLimit) MinAmountValue() Amount { return amountOf(l.MinAmount) }
+func (l *PayoutServiceLimit) MaxAmountValue() Amount { return amountOf(l.MaxAmount) }
+
+func (c *PayoutServiceCommission) FeeAmountValue() Amount { return amountOf(c.FeeAmount) }
+func (c *PayoutServiceCommission) PercentValue() Amount   { return amountOf(c.Percent) }
+
+func (r *BlockedAddressRefundResponse) CommissionValue() Amount { return amountOf(r.Commission) }
+func (r *BlockedAddressRefundResponse) AmountValue() Amount     { return amountOf(r.Amount) }
+
+func (b *WalletBalance) BalanceValue() Amount { return amountOf(b.Balance) }
+
+func (r *ExchangeRate) CourseValue() Amount { return amountOf(r.Course) }
+
+func (w *Webhook) AmountValue() Amount           { return amountOf(w.Amount) }
+func (w *Webhook) PaymentAmountValue() Amount    { return amountOf(w.PaymentAmount) }
+func (w *Webhook) PaymentAmountUSDValue() Amount { return amountOf(w.PaymentAmountUSD) }
+func (w *Webhook) MerchantAmountValue() Amount   { return amountOf(w.MerchantAmount) }
+func (w *Webhook) CommissionValue() Amount       { return amountOf(w.Commission) }
+
+func (c *WebhookConvert) CommissionValue() Amount { return amountOf(c.Commission) }
+func (c *WebhookConvert) RateValue() Amount       { return amountOf(c.Rate) }
+func (c *WebhookConvert) AmountValue() Amount     { return amountOf(c.Amount) }
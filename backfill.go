@@ -0,0 +1,205 @@
+package heleket
+
+import (
+	"context"
+	"iter"
+	"slices"
+	"sync"
+	"time"
+)
+
+// DefaultBackfillWorkers is the number of windows a backfill fetches at
+// once unless WithWorkers is set.
+const DefaultBackfillWorkers = 4
+
+// BackfillOption configures BackfillPayments and BackfillPayouts.
+type BackfillOption func(*backfillConfig)
+
+type backfillConfig struct {
+	workers    int
+	progress   func(BackfillProgress)
+	checkpoint *BackfillCheckpoint
+}
+
+// WithWorkers sets how many windows are fetched concurrently. Requests
+// still go through the client's rate limiter, so this bounds concurrency
+// rather than request rate.
+func WithWorkers(workers int) BackfillOption {
+	return func(c *backfillConfig) {
+		c.workers = workers
+	}
+}
+
+// WithProgress calls fn after each window's items have been yielded.
+func WithProgress(fn func(BackfillProgress)) BackfillOption {
+	return func(c *backfillConfig) {
+		c.progress = fn
+	}
+}
+
+// WithCheckpoint resumes a backfill from a checkpoint reported by an
+// earlier run. Windows that were already yielded are skipped.
+func WithCheckpoint(checkpoint BackfillCheckpoint) BackfillOption {
+	return func(c *backfillConfig) {
+		c.checkpoint = &checkpoint
+	}
+}
+
+// BackfillCheckpoint records how far a backfill got: everything created up
+// to Through has been yielded. Items created exactly at Through may be
+// yielded again after resuming. It is safe to persist as JSON.
+type BackfillCheckpoint struct {
+	Through time.Time `json:"through"`
+}
+
+// BackfillProgress is reported after each window of a backfill.
+type BackfillProgress struct {
+	Window HistoryWindow
+	// Items is the number of items yielded from Window.
+	Items int
+	// WindowsDone and WindowsTotal count the windows of this run.
+	WindowsDone  int
+	WindowsTotal int
+	// Checkpoint resumes the backfill after Window.
+	Checkpoint BackfillCheckpoint
+}
+
+// BackfillPayments fetches the query's windows concurrently and yields the
+// matching payments in chronological order, window by window. At most
+// WithWorkers windows are fetched or buffered at a time. Errors are
+// yielded once and end the sequence; the last reported checkpoint can be
+// used to resume.
+func (q *HistoryQuery) BackfillPayments(ctx context.Context, api PaymentsAPI, opts ...BackfillOption) iter.Seq2[*Payment, error] {
+	return backfillSeq(ctx, q, opts,
+		func(w HistoryWindow) pageFunc[*Payment] { return paymentPages(api, w.From, w.To) },
+		func(p *Payment) string { return p.UUID },
+		func(p *Payment) time.Time { return p.CreatedAt },
+		q.matchPayment)
+}
+
+// BackfillPayouts is BackfillPayments for payouts.
+func (q *HistoryQuery) BackfillPayouts(ctx context.Context, api PayoutsAPI, opts ...BackfillOption) iter.Seq2[*Payout, error] {
+	if q.orderIdPrefix != "" {
+		return errSeq[*Payout](errPayoutOrderId)
+	}
+	return backfillSeq(ctx, q, opts,
+		func(w HistoryWindow) pageFunc[*Payout] { return payoutPages(api, w.From, w.To) },
+		func(p *Payout) string { return p.UUID },
+		func(p *Payout) time.Time { return p.CreatedAt },
+		q.matchPayout)
+}
+
+func backfillSeq[T any](ctx context.Context, q *HistoryQuery, opts []BackfillOption,
+	pages func(HistoryWindow) pageFunc[T], key func(T) string, created func(T) time.Time, match func(T) bool) iter.Seq2[T, error] {
+	cfg := backfillConfig{workers: DefaultBackfillWorkers}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.workers <= 0 {
+		cfg.workers = 1
+	}
+
+	return func(yield func(T, error) bool) {
+		var zero T
+		resumed := *q
+		if cfg.checkpoint != nil && cfg.checkpoint.Through.After(resumed.from) {
+			resumed.from = cfg.checkpoint.Through
+			if !resumed.from.Before(resumed.to) {
+				return
+			}
+		}
+		windows := resumed.Windows()
+
+		ctx, cancel := context.WithCancel(ctx)
+		results := make([]chan page[T], len(windows))
+		for i := range results {
+			results[i] = make(chan page[T], 1)
+		}
+		// A slot is held from the start of a window's fetch until it has
+		// been yielded, which bounds both concurrency and buffering.
+		slots := make(chan struct{}, cfg.workers)
+		var wg sync.WaitGroup
+		defer func() {
+			cancel()
+			wg.Wait()
+		}()
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i, w := range windows {
+				select {
+				case slots <- struct{}{}:
+				case <-ctx.Done():
+					return
+				}
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					var items []T
+					for item, err := range pageSeq(ctx, pages(w)) {
+						if err != nil {
+							results[i] <- page[T]{err: err}
+							return
+						}
+						items = append(items, item)
+					}
+					results[i] <- page[T]{items: items}
+				}()
+			}
+		}()
+
+		var previous map[string]struct{}
+		for i, w := range windows {
+			var p page[T]
+			select {
+			case p = <-results[i]:
+			case <-ctx.Done():
+				yield(zero, ctx.Err())
+				return
+			}
+			if p.err != nil {
+				yield(zero, p.err)
+				return
+			}
+
+			// Only the previous window can hold an item on the shared
+			// boundary, so older keys are dropped to bound memory.
+			seen := make(map[string]struct{}, len(p.items))
+			slices.SortStableFunc(p.items, func(a, b T) int {
+				return created(a).Compare(created(b))
+			})
+			n := 0
+			for _, item := range p.items {
+				if k := key(item); k != "" {
+					if _, dup := previous[k]; dup {
+						continue
+					}
+					if _, dup := seen[k]; dup {
+						continue
+					}
+					seen[k] = struct{}{}
+				}
+				if !match(item) {
+					continue
+				}
+				if !yield(item, nil) {
+					return
+				}
+				n++
+			}
+			previous = seen
+			<-slots
+
+			if cfg.progress != nil {
+				cfg.progress(BackfillProgress{
+					Window:       w,
+					Items:        n,
+					WindowsDone:  i + 1,
+					WindowsTotal: len(windows),
+					Checkpoint:   BackfillCheckpoint{Through: w.To},
+				})
+			}
+		}
+	}
+}
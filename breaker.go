@@ -0,0 +1,209 @@
+package heleket
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without contacting Heleket while the circuit
+// breaker is open.
+var ErrCircuitOpen = errors.New("heleket: circuit breaker is open")
+
+// CircuitState is the state of the circuit breaker.
+type CircuitState int
+
+const (
+	// CircuitClosed lets all calls through.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen rejects all calls with ErrCircuitOpen until the cool-down ends.
+	CircuitOpen
+	// CircuitHalfOpen lets a limited number of trial calls through.
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitBreakerSettings configures the circuit breaker.
+//
+// Only server errors (5xx), timeouts and network failures count as failures.
+// Validation, authorization and other client errors mean Heleket is up and
+// do not trip the breaker.
+type CircuitBreakerSettings struct {
+	// FailureThreshold is the number of consecutive failures that opens the
+	// circuit. Defaults to 5.
+	FailureThreshold int
+	// CoolDown is how long the circuit stays open before trial calls are
+	// let through. Defaults to 30s.
+	CoolDown time.Duration
+	// HalfOpenMaxCalls is the number of concurrent trial calls allowed while
+	// half-open. Defaults to 1.
+	HalfOpenMaxCalls int
+	// OnStateChange, if set, is called on every state transition. It runs
+	// while the breaker is locked and must not call back into the client.
+	OnStateChange func(from, to CircuitState)
+}
+
+// WithCircuitBreaker enables the circuit breaker.
+func WithCircuitBreaker(settings CircuitBreakerSettings) Option {
+	return func(c *Heleket) {
+		c.breaker = newCircuitBreaker(settings)
+	}
+}
+
+// CircuitState returns the current state of the circuit breaker, or
+// CircuitClosed if it is not enabled.
+func (c *Heleket) CircuitState() CircuitState {
+	if c.breaker == nil {
+		return CircuitClosed
+	}
+	return c.breaker.currentState()
+}
+
+type circuitBreaker struct {
+	settings CircuitBreakerSettings
+
+	mu       sync.Mutex
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	trials   int
+	// generation changes on every state transition, so that outcomes of
+	// calls admitted in an earlier state can be told apart.
+	generation uint64
+}
+
+// breakerTicket records the state a call was admitted in.
+type breakerTicket struct {
+	generation uint64
+	trial      bool
+}
+
+func newCircuitBreaker(settings CircuitBreakerSettings) *circuitBreaker {
+	if settings.FailureThreshold <= 0 {
+		settings.FailureThreshold = 5
+	}
+	if settings.CoolDown <= 0 {
+		settings.CoolDown = 30 * time.Second
+	}
+	if settings.HalfOpenMaxCalls <= 0 {
+		settings.HalfOpenMaxCalls = 1
+	}
+	return &circuitBreaker{settings: settings}
+}
+
+func (b *circuitBreaker) currentState() CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.advance(time.Now())
+	return b.state
+}
+
+// allow reports whether a call may proceed, reserving a trial slot when
+// half-open. The ticket must be passed to record or release.
+func (b *circuitBreaker) allow() (breakerTicket, error) {
+	if b == nil {
+		return breakerTicket{}, nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.advance(time.Now())
+
+	ticket := breakerTicket{generation: b.generation}
+	switch b.state {
+	case CircuitOpen:
+		return ticket, ErrCircuitOpen
+	case CircuitHalfOpen:
+		if b.trials >= b.settings.HalfOpenMaxCalls {
+			return ticket, ErrCircuitOpen
+		}
+		b.trials++
+		ticket.trial = true
+	}
+	return ticket, nil
+}
+
+// record updates the breaker with the outcome of a call let through by
+// allow. Outcomes of calls admitted before the last state transition are
+// ignored: a slow call admitted while closed must neither free a trial slot
+// nor close a half-open circuit.
+func (b *circuitBreaker) record(ticket breakerTicket, err error) {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if ticket.generation != b.generation {
+		return
+	}
+	halfOpen := ticket.trial
+	if halfOpen {
+		b.trials--
+	}
+
+	switch ErrorClass(err) {
+	case ErrorClassServer, ErrorClassTimeout, ErrorClassNetwork:
+		b.failures++
+		if halfOpen || (b.state == CircuitClosed && b.failures >= b.settings.FailureThreshold) {
+			b.setState(CircuitOpen)
+			b.openedAt = time.Now()
+		}
+	case ErrorClassCanceled:
+		// The caller gave up; this says nothing about Heleket's health.
+	default:
+		b.failures = 0
+		if halfOpen {
+			b.setState(CircuitClosed)
+		}
+	}
+}
+
+// release gives back a slot reserved by allow for a call that was never made.
+func (b *circuitBreaker) release(ticket breakerTicket) {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if ticket.trial && ticket.generation == b.generation && b.trials > 0 {
+		b.trials--
+	}
+}
+
+// advance moves an open circuit to half-open once the cool-down has passed.
+func (b *circuitBreaker) advance(now time.Time) {
+	if b.state == CircuitOpen && now.Sub(b.openedAt) >= b.settings.CoolDown {
+		b.setState(CircuitHalfOpen)
+		b.trials = 0
+	}
+}
+
+func (b *circuitBreaker) setState(state CircuitState) {
+	if b.state == state {
+		return
+	}
+	from := b.state
+	b.state = state
+	b.generation++
+	if state == CircuitClosed {
+		b.failures = 0
+	}
+	if b.settings.OnStateChange != nil {
+		b.settings.OnStateChange(from, state)
+	}
+}
@@ -0,0 +1,86 @@
+package heleket
+
+import (
+	"context"
+	"time"
+)
+
+// PaymentsAPI covers invoices, their history and refunds.
+type PaymentsAPI interface {
+	CreateInvoice(invoiceReq *InvoiceRequest) (*Payment, error)
+	CreateInvoiceContext(ctx context.Context, invoiceReq *InvoiceRequest) (*Payment, error)
+	GeneratePaymentQRCode(paymentUUID string) (string, error)
+	GeneratePaymentQRCodeContext(ctx context.Context, paymentUUID string) (string, error)
+	GetPaymentInfo(paymentInfoReq *PaymentInfoRequest) (*Payment, error)
+	GetPaymentInfoContext(ctx context.Context, paymentInfoReq *PaymentInfoRequest) (*Payment, error)
+	GetPaymentHistory(dateFrom time.Time, dateTo time.Time, cursor string) (*PaymentHistoryResponse, error)
+	GetPaymentHistoryContext(ctx context.Context, dateFrom time.Time, dateTo time.Time, cursor string) (*PaymentHistoryResponse, error)
+	GetPaymentServicesList() ([]*PaymentService, error)
+	GetPaymentServicesListContext(ctx context.Context) ([]*PaymentService, error)
+	Refund(refundRequest *RefundRequest) (bool, error)
+	RefundContext(ctx context.Context, refundRequest *RefundRequest) (bool, error)
+}
+
+// PayoutsAPI covers payouts and their history.
+type PayoutsAPI interface {
+	CreatePayout(payoutReq *PayoutRequest) (*Payout, error)
+	CreatePayoutContext(ctx context.Context, payoutReq *PayoutRequest) (*Payout, error)
+	GetPayoutInfo(payoutInfoReq *PayoutInfoRequest) (*Payout, error)
+	GetPayoutInfoContext(ctx context.Context, payoutInfoReq *PayoutInfoRequest) (*Payout, error)
+	GetPayoutHistory(dateFrom time.Time, dateTo time.Time, cursor string) (*PayoutHistoryResponse, error)
+	GetPayoutHistoryContext(ctx context.Context, dateFrom time.Time, dateTo time.Time, cursor string) (*PayoutHistoryResponse, error)
+	GetPayoutServicesList() ([]*PayoutService, error)
+	GetPayoutServicesListContext(ctx context.Context) ([]*PayoutService, error)
+}
+
+// WalletsAPI covers static wallets and blocked addresses.
+type WalletsAPI interface {
+	CreateStaticWallet(staticWalletReq *StaticWalletRequest) (*StaticWalletResponse, error)
+	CreateStaticWalletContext(ctx context.Context, staticWalletReq *StaticWalletRequest) (*StaticWalletResponse, error)
+	GenerateStaticWalletQRCode(walletUUID string) (string, error)
+	GenerateStaticWalletQRCodeContext(ctx context.Context, walletUUID string) (string, error)
+	BlockAddress(blockAddressReq *BlockAddressRequest) (*BlockAddressResponse, error)
+	BlockAddressContext(ctx context.Context, blockAddressReq *BlockAddressRequest) (*BlockAddressResponse, error)
+	BlockedAddressRefund(refundRequest *BlockedAddressRefundRequest) (*BlockedAddressRefundResponse, error)
+	BlockedAddressRefundContext(ctx context.Context, refundRequest *BlockedAddressRefundRequest) (*BlockedAddressRefundResponse, error)
+}
+
+// WebhooksAPI covers receiving, verifying, resending and testing webhooks.
+type WebhooksAPI interface {
+	ParseWebhook(reqBody []byte, verifySign bool) (*Webhook, error)
+	ParseWebhookContext(ctx context.Context, reqBody []byte, verifySign bool) (*Webhook, error)
+	VerifySign(apiKey string, reqBody []byte) error
+	VerifySignContext(ctx context.Context, apiKey string, reqBody []byte) error
+	ResendWebhook(resendRequest *ResendWebhookRequest) (bool, error)
+	ResendWebhookContext(ctx context.Context, resendRequest *ResendWebhookRequest) (bool, error)
+	TestPaymentWebhook(testRequest *TestWebhookRequest) (*TestWebhookResponse, error)
+	TestPaymentWebhookContext(ctx context.Context, testRequest *TestWebhookRequest) (*TestWebhookResponse, error)
+	TestPayoutWebhook(testRequest *TestWebhookRequest) (*TestWebhookResponse, error)
+	TestPayoutWebhookContext(ctx context.Context, testRequest *TestWebhookRequest) (*TestWebhookResponse, error)
+	TestWalletWebhook(testRequest *TestWebhookRequest) (*TestWebhookResponse, error)
+	TestWalletWebhookContext(ctx context.Context, testRequest *TestWebhookRequest) (*TestWebhookResponse, error)
+}
+
+// AccountAPI covers balances, discounts and exchange rates.
+type AccountAPI interface {
+	GetBalance() (*BalanceInfo, error)
+	GetBalanceContext(ctx context.Context) (*BalanceInfo, error)
+	GetDiscountsList() ([]*Discount, error)
+	GetDiscountsListContext(ctx context.Context) ([]*Discount, error)
+	SetDiscount(req *SetDiscountRequest) (*Discount, error)
+	SetDiscountContext(ctx context.Context, req *SetDiscountRequest) (*Discount, error)
+	GetExchangeRates(currency string) ([]*ExchangeRate, error)
+	GetExchangeRatesContext(ctx context.Context, currency string) ([]*ExchangeRate, error)
+}
+
+// Client is the complete Heleket API. *Heleket implements it; depend on
+// Client or one of the narrower interfaces to substitute mocks or decorators.
+type Client interface {
+	PaymentsAPI
+	PayoutsAPI
+	WalletsAPI
+	WebhooksAPI
+	AccountAPI
+}
+
+var _ Client = (*Heleket)(nil)
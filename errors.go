@@ -0,0 +1,123 @@
+package heleket
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Sentinel errors that an *APIError can be matched against with errors.Is.
+var (
+	ErrNotFound          = errors.New("heleket: not found")
+	ErrValidation        = errors.New("heleket: validation failed")
+	ErrUnauthorized      = errors.New("heleket: unauthorized")
+	ErrInsufficientFunds = errors.New("heleket: insufficient funds")
+	ErrRateLimited       = errors.New("heleket: rate limited")
+)
+
+// APIError is returned when Heleket rejects a request, either with a non-2xx
+// HTTP status or with a non-zero "state" in the response body.
+type APIError struct {
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+	// State is the "state" value from the response body.
+	State int8
+	// Message is the top-level "message" from the response body.
+	Message string
+	// Errors holds per-field validation messages keyed by field name.
+	Errors map[string][]string
+}
+
+func (e *APIError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "heleket: status=%d state=%d", e.StatusCode, e.State)
+	if e.Message != "" {
+		fmt.Fprintf(&b, " message=%q", e.Message)
+	}
+	if len(e.Errors) > 0 {
+		fields := make([]string, 0, len(e.Errors))
+		for field := range e.Errors {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		for _, field := range fields {
+			fmt.Fprintf(&b, " %s=%q", field, strings.Join(e.Errors[field], "; "))
+		}
+	}
+	return b.String()
+}
+
+// Is reports whether the error belongs to one of the sentinel error classes.
+func (e *APIError) Is(target error) bool {
+	message := strings.ToLower(e.Message)
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound || strings.Contains(message, "not found")
+	case ErrValidation:
+		return e.StatusCode == http.StatusUnprocessableEntity || len(e.Errors) > 0
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden ||
+			strings.Contains(message, "unauthorized") || strings.Contains(message, "invalid sign")
+	case ErrInsufficientFunds:
+		return strings.Contains(message, "insufficient") || strings.Contains(message, "not enough")
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	}
+	return false
+}
+
+type errorRawResponse struct {
+	State   int8            `json:"state"`
+	Message string          `json:"message"`
+	Errors  json.RawMessage `json:"errors"`
+}
+
+// checkResponse records the "state" of res and returns an *APIError if the
+// response signals a failure.
+func checkResponse(res *Response) error {
+	envelope := &errorRawResponse{}
+	envelopeErr := json.Unmarshal(res.Body, envelope)
+	if envelopeErr == nil {
+		res.State = envelope.State
+	}
+
+	if res.StatusCode >= 200 && res.StatusCode <= 299 && res.State == 0 {
+		return nil
+	}
+
+	apiErr := &APIError{StatusCode: res.StatusCode, State: res.State, Message: envelope.Message}
+	if envelopeErr != nil {
+		apiErr.Message = strings.TrimSpace(string(res.Body))
+		if apiErr.Message == "" {
+			apiErr.Message = http.StatusText(res.StatusCode)
+		}
+	}
+	apiErr.Errors = parseFieldErrors(envelope.Errors)
+	return apiErr
+}
+
+// parseFieldErrors accepts both {"field": ["msg", ...]} and {"field": "msg"}.
+func parseFieldErrors(raw json.RawMessage) map[string][]string {
+	if len(raw) == 0 {
+		return nil
+	}
+
+	var list map[string][]string
+	if err := json.Unmarshal(raw, &list); err == nil {
+		return list
+	}
+
+	var single map[string]string
+	if err := json.Unmarshal(raw, &single); err == nil {
+		list = make(map[string][]string, len(single))
+		for field, message := range single {
+			list[field] = []string{message}
+		}
+		return list
+	}
+
+	return nil
+}
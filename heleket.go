@@ -2,59 +2,229 @@ package heleket
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"io"
 	"net/http"
+	"time"
 )
 
 const apiUrl = "https://api.heleket.com/v1"
 
+// KeyKind identifies which of the merchant's API keys signs a request.
+type KeyKind int
+
+const (
+	PaymentKey KeyKind = iota
+	PayoutKey
+)
+
+func (k KeyKind) String() string {
+	switch k {
+	case PaymentKey:
+		return "payment"
+	case PayoutKey:
+		return "payout"
+	default:
+		return "unknown"
+	}
+}
+
 type Heleket struct {
 	merchant      string
 	paymentApiKey string
 	payoutApiKey  string
 	client        *http.Client
+	baseURL       string
+	userAgent     string
+	headers       http.Header
+	timeout       time.Duration
+	retry         RetryPolicy
+	limiter       *rateLimiter
+	middleware    []Middleware
+	doer          Doer
+	tracer        Tracer
+	metrics       MetricsCollector
+	breaker       *circuitBreaker
+	registry      *Registry
+	services      *servicesCache
+	apiLocation   *time.Location
+
+	skipAddressCheck bool
 }
 
-func New(client *http.Client, merchant, paymentApiKey, payoutApiKey string) *Heleket {
-	return &Heleket{
+// New creates a Heleket client.
+//
+// Every API method has a Context variant (e.g. CreateInvoiceContext) that
+// accepts a context.Context for cancellation, deadlines and request-scoped
+// values; the plain variants use context.Background().
+func New(client *http.Client, merchant, paymentApiKey, payoutApiKey string, opts ...Option) *Heleket {
+	c := &Heleket{
 		client:        client,
 		merchant:      merchant,
 		paymentApiKey: paymentApiKey,
 		payoutApiKey:  payoutApiKey,
+		baseURL:       apiUrl,
+		headers:       make(http.Header),
+		tracer:        NoopTracer{},
+		metrics:       noopMetrics{},
+		registry:      DefaultRegistry,
+		apiLocation:   time.UTC,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.client == nil {
+		c.client = http.DefaultClient
+	}
+	if c.apiLocation == nil {
+		c.apiLocation = time.UTC
+	}
+	if c.registry == nil {
+		c.registry = DefaultRegistry
 	}
+	if c.tracer == nil {
+		c.tracer = NoopTracer{}
+	}
+	if c.metrics == nil {
+		c.metrics = noopMetrics{}
+	}
+	c.doer = chain(DoerFunc(c.send), c.middleware)
+
+	return c
 }
 
-func (c *Heleket) fetch(method string, endpoint string, payload any, apiKey string) (*http.Response, error) {
+// fetch performs a call and decodes the "result" envelope into response.
+func (c *Heleket) fetch(ctx context.Context, method string, endpoint string, payload any, keyKind KeyKind, response any) (err error) {
 	var body []byte
-	var err error
 
 	// For GET requests, payload should be nil. Signature is on an empty string.
 	// For POST requests with no parameters, payload should be an empty map or struct, which marshals to "{}".
 	if payload != nil {
 		body, err = json.Marshal(payload)
 		if err != nil {
-			return nil, err
+			return err
 		}
 	} else {
 		body = []byte("")
 	}
 
-	sign := c.signRequest(apiKey, body)
+	path := endpointPath(endpoint)
+	ctx, span := c.tracer.Start(ctx, "heleket "+path)
+	defer span.End()
+	span.SetAttributes(
+		Attribute{AttrEndpoint, path},
+		Attribute{AttrMethod, method},
+		Attribute{AttrKey, keyKind.String()},
+		Attribute{AttrMerchant, c.merchant},
+	)
+	if ids := identifiers(body); ids.OrderId != "" {
+		span.SetAttributes(Attribute{AttrOrderId, ids.OrderId})
+	}
+
+	start := time.Now()
+	res, attempts, err := c.roundTrip(ctx, method, endpoint, body, keyKind)
+	defer func() {
+		c.metrics.ObserveRequest(path, keyKind, time.Since(start), ErrorClass(err))
+	}()
+	span.SetAttributes(Attribute{AttrAttempts, attempts})
+	if res != nil {
+		span.SetAttributes(Attribute{AttrStatusCode, res.StatusCode}, Attribute{AttrState, res.State})
+	}
+	if err == nil {
+		err = json.Unmarshal(res.Body, response)
+	}
+	if err != nil {
+		span.RecordError(err)
+	}
+	return err
+}
+
+// roundTrip sends body through the middleware chain, applying the default
+// timeout, rate limits and retry policy. It returns the last response seen
+// and the number of attempts made.
+func (c *Heleket) roundTrip(ctx context.Context, method, endpoint string, body []byte, keyKind KeyKind) (*Response, int, error) {
+	if _, ok := ctx.Deadline(); !ok && c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	var res *Response
+	canRetry := c.retry.allows(method, endpoint, body)
+	for attempt := 1; ; attempt++ {
+		ticket, err := c.breaker.allow()
+		if err != nil {
+			return res, attempt - 1, err
+		}
+		if err := c.limiter.wait(ctx, endpoint, keyKind); err != nil {
+			c.breaker.release(ticket)
+			return res, attempt - 1, err
+		}
+
+		req := &Request{
+			Method:   method,
+			Endpoint: endpoint,
+			KeyKind:  keyKind,
+			Body:     body,
+			Header:   c.headers.Clone(),
+			Attempt:  attempt,
+		}
+		res, err = c.doer.Do(ctx, req)
+		c.breaker.record(ticket, err)
+		if !canRetry || attempt >= c.retry.MaxAttempts || !retryable(ctx, err) {
+			return res, attempt, err
+		}
 
+		c.metrics.ObserveRetry(endpointPath(endpoint), keyKind)
+		if err = sleep(ctx, c.retry.delay(attempt, res)); err != nil {
+			return res, attempt, err
+		}
+	}
+}
+
+func (c *Heleket) apiKey(keyKind KeyKind) string {
+	if keyKind == PayoutKey {
+		return c.payoutApiKey
+	}
+	return c.paymentApiKey
+}
+
+// send signs and performs a single HTTP attempt. It is the innermost Doer.
+func (c *Heleket) send(ctx context.Context, r *Request) (*Response, error) {
 	var reqBody io.Reader
-	if method != http.MethodGet {
-		reqBody = bytes.NewBuffer(body)
+	if r.Method != http.MethodGet {
+		reqBody = bytes.NewReader(r.Body)
 	}
 
-	req, err := http.NewRequest(method, apiUrl+endpoint, reqBody)
+	req, err := http.NewRequestWithContext(ctx, r.Method, c.baseURL+r.Endpoint, reqBody)
 	if err != nil {
 		return nil, err
 	}
 
+	for key, values := range r.Header {
+		req.Header[key] = append([]string(nil), values...)
+	}
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("merchant", c.merchant)
-	req.Header.Set("sign", sign)
-	res, err := c.client.Do(req)
-	return res, err
+	req.Header.Set("sign", c.signRequest(c.apiKey(r.KeyKind), r.Body))
+
+	httpRes, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer httpRes.Body.Close()
+
+	body, err := io.ReadAll(httpRes.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &Response{StatusCode: httpRes.StatusCode, Header: httpRes.Header, Body: body}
+	return res, checkResponse(res)
 }
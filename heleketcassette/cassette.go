@@ -0,0 +1,291 @@
+// Package heleketcassette provides an http.RoundTripper that records Heleket
+// API sessions into fixture files and replays them later, so tests can run
+// offline and deterministically.
+//
+// Record a session once against the real API:
+//
+//	rec := heleketcassette.Record("testdata/invoice.json", heleketcassette.Options{
+//		Secrets: []string{merchant, paymentApiKey, payoutApiKey},
+//	})
+//	client := heleket.New(rec.Client(), merchant, paymentApiKey, payoutApiKey)
+//	// ... make calls ...
+//	err := rec.Save()
+//
+// and replay it in tests:
+//
+//	rep, err := heleketcassette.Replay("testdata/invoice.json")
+//	client := heleket.New(rep.Client(), "merchant", "payment-key", "payout-key")
+//
+// The sign header is always scrubbed, as are any configured secrets. A
+// replayed request that was not recorded fails with ErrUnrecorded.
+package heleketcassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Redacted replaces scrubbed values in recorded fixtures.
+const Redacted = "[REDACTED]"
+
+// ErrUnrecorded is returned when replaying a request that is not in the cassette.
+var ErrUnrecorded = errors.New("heleketcassette: unrecorded request")
+
+// Interaction is a recorded request/response pair.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is the scrubbed form of an outgoing request.
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	Path   string      `json:"path"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// RecordedResponse is the scrubbed form of a response.
+type RecordedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// Cassette is the content of a fixture file.
+type Cassette struct {
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// Options configures recording.
+type Options struct {
+	// Transport performs the real requests. Defaults to http.DefaultTransport.
+	Transport http.RoundTripper
+	// Secrets are values, such as the merchant id and API keys, that are
+	// replaced with Redacted wherever they appear in a recording.
+	Secrets []string
+	// ScrubHeaders lists extra headers whose values are replaced with
+	// Redacted. The sign header is always scrubbed.
+	ScrubHeaders []string
+}
+
+// Transport records or replays Heleket HTTP traffic.
+type Transport struct {
+	path      string
+	recording bool
+	options   Options
+
+	mu       sync.Mutex
+	cassette *Cassette
+	used     []bool
+}
+
+// Record returns a Transport that forwards requests to the real transport
+// and keeps scrubbed copies of every interaction until Save is called.
+func Record(path string, options Options) *Transport {
+	if options.Transport == nil {
+		options.Transport = http.DefaultTransport
+	}
+	return &Transport{path: path, recording: true, options: options, cassette: &Cassette{}}
+}
+
+// Replay loads the cassette at path and returns a Transport that serves
+// requests from it without touching the network.
+func Replay(path string) (*Transport, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cassette := &Cassette{}
+	if err = json.Unmarshal(data, cassette); err != nil {
+		return nil, fmt.Errorf("heleketcassette: %s: %w", path, err)
+	}
+
+	return &Transport{path: path, cassette: cassette, used: make([]bool, len(cassette.Interactions))}, nil
+}
+
+// Client returns an *http.Client using t, for passing to heleket.New.
+func (t *Transport) Client() *http.Client {
+	return &http.Client{Transport: t}
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		// A RoundTripper must not modify req, so the body is read from a
+		// fresh copy where possible and sent on with a clone.
+		var err error
+		rc := req.Body
+		if req.GetBody != nil {
+			req.Body.Close()
+			if rc, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+		body, err = io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	if t.recording {
+		return t.record(req, body)
+	}
+	return t.replay(req, body)
+}
+
+func (t *Transport) record(req *http.Request, body []byte) (*http.Response, error) {
+	res, err := t.options.Transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	resBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	interaction := &Interaction{
+		Request: RecordedRequest{
+			Method: req.Method,
+			Path:   t.scrub(req.URL.RequestURI()),
+			Header: t.scrubHeader(req.Header),
+			Body:   t.scrub(string(body)),
+		},
+		Response: RecordedResponse{
+			StatusCode: res.StatusCode,
+			Header:     t.scrubHeader(res.Header),
+			Body:       t.scrub(string(resBody)),
+		},
+	}
+
+	t.mu.Lock()
+	t.cassette.Interactions = append(t.cassette.Interactions, interaction)
+	t.mu.Unlock()
+
+	res.Body = io.NopCloser(bytes.NewReader(resBody))
+	return res, nil
+}
+
+func (t *Transport) replay(req *http.Request, body []byte) (*http.Response, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for i, interaction := range t.cassette.Interactions {
+		if t.used[i] || !matches(interaction.Request, req, body) {
+			continue
+		}
+		t.used[i] = true
+
+		recorded := interaction.Response
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", recorded.StatusCode, http.StatusText(recorded.StatusCode)),
+			StatusCode:    recorded.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        recorded.Header.Clone(),
+			Body:          io.NopCloser(strings.NewReader(recorded.Body)),
+			ContentLength: int64(len(recorded.Body)),
+			Request:       req,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s %s %s (cassette %s)", ErrUnrecorded, req.Method, req.URL.RequestURI(), body, t.path)
+}
+
+// matches compares method, path and body. JSON bodies are compared
+// structurally so that key order does not matter.
+func matches(recorded RecordedRequest, req *http.Request, body []byte) bool {
+	if recorded.Method != req.Method || recorded.Path != req.URL.RequestURI() {
+		return false
+	}
+	if recorded.Body == string(body) {
+		return true
+	}
+
+	var a, b any
+	if json.Unmarshal([]byte(recorded.Body), &a) != nil || json.Unmarshal(body, &b) != nil {
+		return false
+	}
+	ja, _ := json.Marshal(a)
+	jb, _ := json.Marshal(b)
+	return bytes.Equal(ja, jb)
+}
+
+// Save writes the recorded interactions to the cassette file, creating
+// parent directories as needed. It is a no-op when replaying.
+func (t *Transport) Save() error {
+	if !t.recording {
+		return nil
+	}
+
+	t.mu.Lock()
+	data, err := json.MarshalIndent(t.cassette, "", "  ")
+	t.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(t.path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(t.path, append(data, '\n'), 0o644)
+}
+
+// Unused returns the recorded interactions that have not been replayed yet.
+// It returns nil for a recording Transport.
+func (t *Transport) Unused() []*Interaction {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.recording {
+		return nil
+	}
+	var unused []*Interaction
+	for i, interaction := range t.cassette.Interactions {
+		if !t.used[i] {
+			unused = append(unused, interaction)
+		}
+	}
+	return unused
+}
+
+func (t *Transport) scrub(s string) string {
+	for _, secret := range t.options.Secrets {
+		if secret != "" {
+			s = strings.ReplaceAll(s, secret, Redacted)
+		}
+	}
+	return s
+}
+
+func (t *Transport) scrubHeader(header http.Header) http.Header {
+	scrubbed := make(http.Header, len(header))
+	for key, values := range header {
+		scrubbed[key] = make([]string, len(values))
+		for i, value := range values {
+			scrubbed[key][i] = t.scrub(value)
+		}
+	}
+
+	for _, key := range append([]string{"sign"}, t.options.ScrubHeaders...) {
+		if _, ok := scrubbed[http.CanonicalHeaderKey(key)]; ok {
+			scrubbed.Set(key, Redacted)
+		}
+	}
+	return scrubbed
+}
@@ -0,0 +1,333 @@
+// Package heleketexport writes payments and payouts as CSV, JSON Lines or
+// OFX/QFX statements for finance and accounting tools.
+//
+// Every exporter takes the records as an iterator, such as a history query
+// or a heleketsync store, and streams them to the writer one at a time:
+//
+//	q := heleket.NewHistoryQuery(from, to).Status(heleket.StatusPaid)
+//	err := heleketexport.PaymentsCSV(w, q.Payments(ctx, client),
+//		heleketexport.WithColumns("created_at", "order_id", "amount", "currency", "commission"))
+//
+// Amounts are padded to the precision of their currency, see
+// heleket.CurrencyPrecision, and keep any further digits Heleket reported:
+// an export never rounds.
+package heleketexport
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"strconv"
+	"time"
+
+	"github.com/idanyas/heleket-go"
+)
+
+// ErrUnknownColumn is returned for a column name the exporter does not know.
+var ErrUnknownColumn = errors.New("heleketexport: unknown column")
+
+// Option configures a CSV or JSON Lines export.
+type Option func(*config)
+
+type config struct {
+	columns  []string
+	noHeader bool
+	comma    rune
+}
+
+// WithColumns selects the columns to export, in order. Defaults to
+// PaymentColumns or PayoutColumns.
+func WithColumns(names ...string) Option {
+	return func(c *config) {
+		c.columns = names
+	}
+}
+
+// WithoutHeader leaves out the CSV header row.
+func WithoutHeader() Option {
+	return func(c *config) {
+		c.noHeader = true
+	}
+}
+
+// WithComma sets the CSV field delimiter. Defaults to ','.
+func WithComma(comma rune) Option {
+	return func(c *config) {
+		c.comma = comma
+	}
+}
+
+type column[T any] struct {
+	name  string
+	value func(T) any
+}
+
+// paymentColumns lists every payment column. Amounts in the payer's
+// currency fall back to the invoice currency until the payer has chosen.
+var paymentColumns = []column[*heleket.Payment]{
+	{"uuid", func(p *heleket.Payment) any { return p.UUID }},
+	{"order_id", func(p *heleket.Payment) any { return p.OrderId }},
+	{"created_at", func(p *heleket.Payment) any { return p.CreatedAt }},
+	{"updated_at", func(p *heleket.Payment) any { return p.UpdatedAt }},
+	{"status", func(p *heleket.Payment) any { return string(paymentStatus(p)) }},
+	{"is_final", func(p *heleket.Payment) any { return p.IsFinal }},
+	{"currency", func(p *heleket.Payment) any { return p.Currency }},
+	{"amount", func(p *heleket.Payment) any { return amountString(p.AmountValue(), p.Currency) }},
+	{"network", func(p *heleket.Payment) any { return p.Network }},
+	{"payer_currency", func(p *heleket.Payment) any { return p.PayerCurrency }},
+	{"payer_amount", func(p *heleket.Payment) any { return amountString(p.PayerAmountValue(), payerCurrency(p)) }},
+	{"payment_amount", func(p *heleket.Payment) any { return amountString(p.PaymentAmountValue(), payerCurrency(p)) }},
+	{"payment_amount_usd", func(p *heleket.Payment) any { return amountString(p.PaymentAmountUSDValue(), "USD") }},
+	{"exchange_rate", func(p *heleket.Payment) any { return p.PayerAmountExchangeRateValue().String() }},
+	{"discount_percent", func(p *heleket.Payment) any { return p.DiscountPercent }},
+	{"discount", func(p *heleket.Payment) any { return amountString(p.DiscountValue(), payerCurrency(p)) }},
+	{"merchant_amount", func(p *heleket.Payment) any { return amountString(p.MerchantAmountValue(), payerCurrency(p)) }},
+	{"commission", func(p *heleket.Payment) any { return amountString(p.CommissionValue(), payerCurrency(p)) }},
+	{"convert_to_currency", func(p *heleket.Payment) any {
+		return convertField(p, func(c *heleket.PaymentConvert) string { return c.ToCurrency })
+	}},
+	{"convert_amount", func(p *heleket.Payment) any {
+		return convertField(p, func(c *heleket.PaymentConvert) string { return amountString(c.AmountValue(), c.ToCurrency) })
+	}},
+	{"convert_commission", func(p *heleket.Payment) any {
+		return convertField(p, func(c *heleket.PaymentConvert) string { return amountString(c.CommissionValue(), c.ToCurrency) })
+	}},
+	{"convert_rate", func(p *heleket.Payment) any {
+		return convertField(p, func(c *heleket.PaymentConvert) string { return c.RateValue().String() })
+	}},
+	{"address", func(p *heleket.Payment) any { return p.Address }},
+	{"from", func(p *heleket.Payment) any { return p.From }},
+	{"txid", func(p *heleket.Payment) any { return p.TxId }},
+	{"additional_data", func(p *heleket.Payment) any { return p.AdditionalData }},
+	{"comments", func(p *heleket.Payment) any { return p.Comments }},
+}
+
+var payoutColumns = []column[*heleket.Payout]{
+	{"uuid", func(p *heleket.Payout) any { return p.UUID }},
+	{"created_at", func(p *heleket.Payout) any { return p.CreatedAt }},
+	{"updated_at", func(p *heleket.Payout) any { return p.UpdatedAt }},
+	{"status", func(p *heleket.Payout) any { return string(p.Status) }},
+	{"is_final", func(p *heleket.Payout) any { return p.IsFinal }},
+	{"currency", func(p *heleket.Payout) any { return p.Currency }},
+	{"amount", func(p *heleket.Payout) any { return amountString(p.AmountValue(), p.Currency) }},
+	{"network", func(p *heleket.Payout) any { return p.Network }},
+	{"payer_currency", func(p *heleket.Payout) any { return p.PayerCurrency }},
+	{"payer_amount", func(p *heleket.Payout) any { return amountString(p.PayerAmountValue(), payoutPayerCurrency(p)) }},
+	{"balance", func(p *heleket.Payout) any { return amountString(p.BalanceValue(), payoutPayerCurrency(p)) }},
+	{"address", func(p *heleket.Payout) any { return p.Address }},
+	{"txid", func(p *heleket.Payout) any { return p.TxId }},
+}
+
+// PaymentColumns and PayoutColumns list the available columns, in the
+// default order.
+var (
+	PaymentColumns = columnNames(paymentColumns)
+	PayoutColumns  = columnNames(payoutColumns)
+)
+
+// PaymentsCSV writes payments as CSV with a header row.
+func PaymentsCSV(w io.Writer, payments iter.Seq2[*heleket.Payment, error], opts ...Option) error {
+	return writeCSV(w, payments, paymentColumns, opts)
+}
+
+// PayoutsCSV writes payouts as CSV with a header row.
+func PayoutsCSV(w io.Writer, payouts iter.Seq2[*heleket.Payout, error], opts ...Option) error {
+	return writeCSV(w, payouts, payoutColumns, opts)
+}
+
+// PaymentsJSONL writes payments as JSON Lines, one object per payment
+// keyed by column name. Empty amounts and times are null.
+func PaymentsJSONL(w io.Writer, payments iter.Seq2[*heleket.Payment, error], opts ...Option) error {
+	return writeJSONL(w, payments, paymentColumns, opts)
+}
+
+// PayoutsJSONL writes payouts as JSON Lines. See PaymentsJSONL.
+func PayoutsJSONL(w io.Writer, payouts iter.Seq2[*heleket.Payout, error], opts ...Option) error {
+	return writeJSONL(w, payouts, payoutColumns, opts)
+}
+
+func writeCSV[T any](w io.Writer, records iter.Seq2[T, error], all []column[T], opts []Option) error {
+	cfg, columns, err := configure(all, opts)
+	if err != nil {
+		return err
+	}
+
+	cw := csv.NewWriter(w)
+	if cfg.comma != 0 {
+		cw.Comma = cfg.comma
+	}
+	if !cfg.noHeader {
+		if err := cw.Write(columnNames(columns)); err != nil {
+			return err
+		}
+	}
+
+	row := make([]string, len(columns))
+	for record, err := range records {
+		if err != nil {
+			cw.Flush()
+			return err
+		}
+		for i, c := range columns {
+			row[i] = csvCell(c.value(record))
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func writeJSONL[T any](w io.Writer, records iter.Seq2[T, error], all []column[T], opts []Option) error {
+	_, columns, err := configure(all, opts)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(w)
+	for record, err := range records {
+		if err != nil {
+			return err
+		}
+		obj := make(orderedObject, len(columns))
+		for i, c := range columns {
+			obj[i] = field{c.name, jsonValue(c.value(record))}
+		}
+		if err := enc.Encode(obj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func configure[T any](all []column[T], opts []Option) (config, []column[T], error) {
+	var cfg config
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if len(cfg.columns) == 0 {
+		return cfg, all, nil
+	}
+
+	byName := make(map[string]column[T], len(all))
+	for _, c := range all {
+		byName[c.name] = c
+	}
+	columns := make([]column[T], len(cfg.columns))
+	for i, name := range cfg.columns {
+		c, ok := byName[name]
+		if !ok {
+			return cfg, nil, fmt.Errorf("%w %q", ErrUnknownColumn, name)
+		}
+		columns[i] = c
+	}
+	return cfg, columns, nil
+}
+
+func columnNames[T any](columns []column[T]) []string {
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = c.name
+	}
+	return names
+}
+
+func csvCell(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case time.Time:
+		if v.IsZero() {
+			return ""
+		}
+		return v.Format(time.RFC3339)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func jsonValue(v any) any {
+	switch v := v.(type) {
+	case string:
+		if v == "" {
+			return nil
+		}
+	case time.Time:
+		if v.IsZero() {
+			return nil
+		}
+	}
+	return v
+}
+
+// orderedObject marshals as a JSON object that keeps its field order.
+type orderedObject []field
+
+type field struct {
+	name  string
+	value any
+}
+
+func (o orderedObject) MarshalJSON() ([]byte, error) {
+	b := []byte{'{'}
+	for i, f := range o {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		name, err := json.Marshal(f.name)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(f.value)
+		if err != nil {
+			return nil, err
+		}
+		b = append(b, name...)
+		b = append(b, ':')
+		b = append(b, value...)
+	}
+	return append(b, '}'), nil
+}
+
+// amountString renders a with at least the precision of currency, keeping
+// every digit of a.
+func amountString(a heleket.Amount, currency string) string {
+	if a.IsNull() {
+		return ""
+	}
+	return a.Round(max(a.Scale(), heleket.CurrencyPrecision(currency)), heleket.RoundHalfUp).String()
+}
+
+func paymentStatus(p *heleket.Payment) heleket.Status {
+	if p.PaymentStatus != "" {
+		return p.PaymentStatus
+	}
+	return p.Status
+}
+
+func payerCurrency(p *heleket.Payment) string {
+	if p.PayerCurrency != "" {
+		return p.PayerCurrency
+	}
+	return p.Currency
+}
+
+func payoutPayerCurrency(p *heleket.Payout) string {
+	if p.PayerCurrency != "" {
+		return p.PayerCurrency
+	}
+	return p.Currency
+}
+
+func convertField(p *heleket.Payment, get func(*heleket.PaymentConvert) string) string {
+	if p.Convert == nil {
+		return ""
+	}
+	return get(p.Convert)
+}
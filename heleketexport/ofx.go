@@ -0,0 +1,281 @@
+package heleketexport
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"strings"
+	"time"
+
+	"github.com/idanyas/heleket-go"
+)
+
+// ofxTimeFormat is the OFX datetime format, always written in UTC.
+const ofxTimeFormat = "20060102150405.000"
+
+// OFXStatement describes the account statement PaymentsOFX and PayoutsOFX
+// write. An OFX statement has a single currency: only records with an
+// amount in Currency are included.
+type OFXStatement struct {
+	// Currency is the statement currency, e.g. "USDT". Required.
+	Currency string
+	// AccountID identifies the account, e.g. the merchant id. Required.
+	AccountID string
+	// BankID identifies the institution. Defaults to "HELEKET".
+	BankID string
+	// From and To are the statement period. They default to the time of
+	// the export.
+	From time.Time
+	To   time.Time
+	// IntuitBID, if set, is written as INTU.BID, which Quicken and
+	// QuickBooks require to import the file as QFX.
+	IntuitBID string
+	// Balance is the ledger balance at To. If null, the balance is the sum
+	// of the exported transactions, as if the account started at zero.
+	Balance heleket.Amount
+}
+
+// PaymentsOFX writes successful payments as credits of an OFX 2.2 bank
+// statement. A payment is credited with its converted amount if it was
+// converted to the statement currency, otherwise with the merchant amount
+// or the amount paid in the payer's currency, otherwise with the invoice
+// amount. The memo carries the commission and conversion breakdown.
+//
+// Payments in the refund flow were paid first and are credited too. A
+// refund_paid payment is followed by a debit for the credited amount, with
+// the FITID of the payment plus "-refund"; payment info does not report
+// partial refund amounts, so adjust those by hand.
+func PaymentsOFX(w io.Writer, payments iter.Seq2[*heleket.Payment, error], stmt OFXStatement) error {
+	return writeOFX(w, payments, stmt, paymentTransactions)
+}
+
+// PayoutsOFX writes successful payouts as debits of an OFX 2.2 bank
+// statement, for the amount taken from the balance, or the amount sent if
+// that is in the statement currency instead.
+func PayoutsOFX(w io.Writer, payouts iter.Seq2[*heleket.Payout, error], stmt OFXStatement) error {
+	return writeOFX(w, payouts, stmt, payoutTransactions)
+}
+
+type ofxTransaction struct {
+	kind   string
+	posted time.Time
+	amount heleket.Amount
+	id     string
+	name   string
+	memo   string
+}
+
+func paymentTransactions(p *heleket.Payment, currency string) []ofxTransaction {
+	status := paymentStatus(p)
+	if !status.IsSuccessful() && !status.IsRefund() {
+		return nil
+	}
+
+	var amount heleket.Amount
+	switch {
+	case p.Convert != nil && strings.EqualFold(p.Convert.ToCurrency, currency):
+		amount = p.Convert.AmountValue()
+	case strings.EqualFold(p.PayerCurrency, currency):
+		amount = p.MerchantAmountValue()
+		if amount.IsNull() {
+			amount = p.PaymentAmountValue()
+		}
+	case strings.EqualFold(p.Currency, currency):
+		amount = p.AmountValue()
+	}
+	if amount.IsNull() {
+		return nil
+	}
+
+	var memo []string
+	if commission := p.CommissionValue(); !commission.IsNull() {
+		memo = append(memo, fmt.Sprintf("commission %s %s", amountString(commission, payerCurrency(p)), payerCurrency(p)))
+	}
+	if c := p.Convert; c != nil {
+		memo = append(memo, fmt.Sprintf("converted to %s %s at %s", amountString(c.AmountValue(), c.ToCurrency), c.ToCurrency, c.RateValue()))
+		if commission := c.CommissionValue(); !commission.IsNull() {
+			memo = append(memo, fmt.Sprintf("conversion commission %s %s", amountString(commission, c.ToCurrency), c.ToCurrency))
+		}
+	}
+	if p.TxId != "" {
+		memo = append(memo, "txid "+p.TxId)
+	}
+
+	// Once refunding, UpdatedAt is the time of the refund, not the payment.
+	posted := p.UpdatedAt
+	if posted.IsZero() || status.IsRefund() {
+		posted = p.CreatedAt
+	}
+	transactions := []ofxTransaction{{
+		kind:   "CREDIT",
+		posted: posted,
+		amount: amount,
+		id:     p.UUID,
+		name:   p.OrderId,
+		memo:   strings.Join(memo, "; "),
+	}}
+	if status == heleket.StatusRefundPaid {
+		refunded := p.UpdatedAt
+		if refunded.IsZero() {
+			refunded = p.CreatedAt
+		}
+		transactions = append(transactions, ofxTransaction{
+			kind:   "DEBIT",
+			posted: refunded,
+			amount: amount.Abs().Neg(),
+			id:     p.UUID + "-refund",
+			name:   p.OrderId,
+			memo:   "refund of " + p.UUID,
+		})
+	}
+	return transactions
+}
+
+func payoutTransactions(p *heleket.Payout, currency string) []ofxTransaction {
+	if !p.Status.IsSuccessful() {
+		return nil
+	}
+
+	var amount heleket.Amount
+	switch {
+	case strings.EqualFold(p.PayerCurrency, currency):
+		amount = p.PayerAmountValue()
+	case strings.EqualFold(p.Currency, currency):
+		amount = p.AmountValue()
+	}
+	if amount.IsNull() {
+		return nil
+	}
+
+	memo := fmt.Sprintf("payout %s %s on %s to %s", amountString(p.AmountValue(), p.Currency), p.Currency, p.Network, p.Address)
+	if p.TxId != "" {
+		memo += "; txid " + p.TxId
+	}
+
+	posted := p.UpdatedAt
+	if posted.IsZero() {
+		posted = p.CreatedAt
+	}
+	return []ofxTransaction{{
+		kind:   "DEBIT",
+		posted: posted,
+		amount: amount.Abs().Neg(),
+		id:     p.UUID,
+		name:   p.Address,
+		memo:   memo,
+	}}
+}
+
+func writeOFX[T any](w io.Writer, records iter.Seq2[T, error], stmt OFXStatement, transactions func(T, string) []ofxTransaction) error {
+	if stmt.Currency == "" || stmt.AccountID == "" {
+		return errors.New("heleketexport: OFX statement needs a currency and an account id")
+	}
+	now := time.Now()
+	if stmt.BankID == "" {
+		stmt.BankID = "HELEKET"
+	}
+	if stmt.From.IsZero() {
+		stmt.From = now
+	}
+	if stmt.To.IsZero() {
+		stmt.To = now
+	}
+	currency := strings.ToUpper(stmt.Currency)
+
+	o := &ofxWriter{w: w}
+	o.raw(`<?xml version="1.0" encoding="UTF-8" standalone="no"?>` + "\n")
+	o.raw(`<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>` + "\n")
+	o.raw("<OFX>\n<SIGNONMSGSRSV1>\n<SONRS>\n")
+	o.status()
+	o.elem("DTSERVER", ofxTime(now))
+	o.elem("LANGUAGE", "ENG")
+	if stmt.IntuitBID != "" {
+		o.elem("INTU.BID", stmt.IntuitBID)
+	}
+	o.raw("</SONRS>\n</SIGNONMSGSRSV1>\n<BANKMSGSRSV1>\n<STMTTRNRS>\n")
+	o.elem("TRNUID", "0")
+	o.status()
+	o.raw("<STMTRS>\n")
+	o.elem("CURDEF", currency)
+	o.raw("<BANKACCTFROM>\n")
+	o.elem("BANKID", stmt.BankID)
+	o.elem("ACCTID", stmt.AccountID)
+	o.elem("ACCTTYPE", "CHECKING")
+	o.raw("</BANKACCTFROM>\n<BANKTRANLIST>\n")
+	o.elem("DTSTART", ofxTime(stmt.From))
+	o.elem("DTEND", ofxTime(stmt.To))
+
+	total := heleket.NewAmount(0, 0)
+	for record, err := range records {
+		if err != nil {
+			return err
+		}
+		for _, t := range transactions(record, currency) {
+			total = total.Add(t.amount)
+			o.raw("<STMTTRN>\n")
+			o.elem("TRNTYPE", t.kind)
+			o.elem("DTPOSTED", ofxTime(t.posted))
+			o.elem("TRNAMT", amountString(t.amount, currency))
+			o.elem("FITID", t.id)
+			if t.name != "" {
+				o.elem("NAME", truncate(t.name, 32))
+			}
+			if t.memo != "" {
+				o.elem("MEMO", truncate(t.memo, 255))
+			}
+			o.raw("</STMTTRN>\n")
+		}
+		if o.err != nil {
+			return o.err
+		}
+	}
+
+	balance := stmt.Balance
+	if balance.IsNull() {
+		balance = total
+	}
+	o.raw("</BANKTRANLIST>\n<LEDGERBAL>\n")
+	o.elem("BALAMT", amountString(balance, currency))
+	o.elem("DTASOF", ofxTime(stmt.To))
+	o.raw("</LEDGERBAL>\n</STMTRS>\n</STMTTRNRS>\n</BANKMSGSRSV1>\n</OFX>\n")
+	return o.err
+}
+
+// ofxWriter writes OFX elements, keeping the first error.
+type ofxWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (o *ofxWriter) raw(s string) {
+	if o.err == nil {
+		_, o.err = io.WriteString(o.w, s)
+	}
+}
+
+func (o *ofxWriter) elem(name, value string) {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(value))
+	o.raw("<" + name + ">" + b.String() + "</" + name + ">\n")
+}
+
+func (o *ofxWriter) status() {
+	o.raw("<STATUS>\n")
+	o.elem("CODE", "0")
+	o.elem("SEVERITY", "INFO")
+	o.raw("</STATUS>\n")
+}
+
+func ofxTime(t time.Time) string {
+	return t.UTC().Format(ofxTimeFormat) + "[0:GMT]"
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n])
+}
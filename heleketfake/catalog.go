@@ -0,0 +1,129 @@
+package heleketfake
+
+import (
+	"math/big"
+
+	"github.com/idanyas/heleket-go"
+)
+
+// network pairs the currencies the fake offers with the networks they run on.
+var networks = []struct {
+	currency string
+	network  string
+	min      string
+	max      string
+}{
+	{"USDT", "tron", "1.00000000", "100000.00000000"},
+	{"USDT", "bsc", "1.00000000", "100000.00000000"},
+	{"USDT", "eth", "10.00000000", "100000.00000000"},
+	{"TRX", "tron", "10.00000000", "1000000.00000000"},
+	{"BTC", "btc", "0.00010000", "10.00000000"},
+	{"LTC", "ltc", "0.01000000", "1000.00000000"},
+	{"ETH", "eth", "0.00500000", "100.00000000"},
+	{"TON", "ton", "0.50000000", "100000.00000000"},
+	{"SOL", "sol", "0.05000000", "10000.00000000"},
+}
+
+func defaultPaymentServices() []*heleket.PaymentService {
+	services := make([]*heleket.PaymentService, 0, len(networks))
+	for _, n := range networks {
+		services = append(services, &heleket.PaymentService{
+			Network:     n.network,
+			Currency:    n.currency,
+			IsAvailable: true,
+			Limit:       &heleket.PaymentServiceLimit{MinAmount: n.min, MaxAmount: n.max},
+			Commission:  &heleket.PaymentServiceCommission{FeeAmount: "0.00000000", Percent: "2.00"},
+		})
+	}
+	return services
+}
+
+func defaultPayoutServices() []*heleket.PayoutService {
+	services := make([]*heleket.PayoutService, 0, len(networks))
+	for _, n := range networks {
+		services = append(services, &heleket.PayoutService{
+			Network:     n.network,
+			Currency:    n.currency,
+			IsAvailable: true,
+			Limit:       &heleket.PayoutServiceLimit{MinAmount: n.min, MaxAmount: n.max},
+			Commission:  &heleket.PayoutServiceCommission{FeeAmount: "0.00000000", Percent: "1.00"},
+		})
+	}
+	return services
+}
+
+func defaultBalances() map[string]*big.Rat {
+	return map[string]*big.Rat{
+		"USDT": big.NewRat(1000, 1),
+		"TRX":  big.NewRat(10000, 1),
+		"BTC":  big.NewRat(1, 10),
+		"ETH":  big.NewRat(1, 1),
+	}
+}
+
+// defaultUSDRates holds the USD price of every currency the fake knows.
+func defaultUSDRates() map[string]*big.Rat {
+	return map[string]*big.Rat{
+		"USD":  big.NewRat(1, 1),
+		"USDT": big.NewRat(1, 1),
+		"EUR":  big.NewRat(108, 100),
+		"TRX":  big.NewRat(25, 100),
+		"BTC":  big.NewRat(60000, 1),
+		"LTC":  big.NewRat(80, 1),
+		"ETH":  big.NewRat(3000, 1),
+		"TON":  big.NewRat(5, 1),
+		"SOL":  big.NewRat(150, 1),
+	}
+}
+
+// addressAlphabets produces plausible deposit addresses per network.
+var addressFormats = map[string]struct {
+	prefix   string
+	alphabet string
+	length   int
+}{
+	"tron": {"T", base58Alphabet, 33},
+	"btc":  {"bc1q", "023456789acdefghjklmnpqrstuvwxyz", 38},
+	"ltc":  {"ltc1q", "023456789acdefghjklmnpqrstuvwxyz", 38},
+	"eth":  {"0x", "0123456789abcdef", 40},
+	"bsc":  {"0x", "0123456789abcdef", 40},
+	"ton":  {"UQ", "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_", 46},
+	"sol":  {"", base58Alphabet, 44},
+}
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// depositAddress derives a deterministic address on network from seed.
+func depositAddress(network, seed string) string {
+	format, ok := addressFormats[network]
+	if !ok {
+		format = addressFormats["tron"]
+	}
+
+	digest := []byte(Sign([]byte(seed), network))
+	out := []byte(format.prefix)
+	for i := 0; i < format.length; i++ {
+		out = append(out, format.alphabet[int(digest[i%len(digest)]+byte(i*7))%len(format.alphabet)])
+	}
+	return string(out)
+}
+
+// usdRate returns the USD price of currency.
+func (s *Server) usdRate(currency string) (*big.Rat, bool) {
+	rate, ok := s.usdRates[currency]
+	return rate, ok
+}
+
+// convert converts amount from one currency to another using USD prices.
+func (s *Server) convert(amount *big.Rat, from, to string) (*big.Rat, bool) {
+	fromRate, ok := s.usdRate(from)
+	if !ok {
+		return nil, false
+	}
+	toRate, ok := s.usdRate(to)
+	if !ok {
+		return nil, false
+	}
+	usd := new(big.Rat).Mul(amount, fromRate)
+	return usd.Quo(usd, toRate), true
+}
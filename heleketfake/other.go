@@ -0,0 +1,92 @@
+package heleketfake
+
+import (
+	"math/big"
+	"net/http"
+	"slices"
+
+	"github.com/idanyas/heleket-go"
+)
+
+func (s *Server) balance(r *http.Request, body []byte) (any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	currencies := make([]string, 0, len(s.balances))
+	for currency := range s.balances {
+		currencies = append(currencies, currency)
+	}
+	slices.Sort(currencies)
+
+	merchant := make([]*heleket.WalletBalance, 0, len(currencies))
+	for _, currency := range currencies {
+		merchant = append(merchant, &heleket.WalletBalance{
+			UUID:         Sign([]byte(currency), s.merchant),
+			Balance:      formatAmount(s.balances[currency]),
+			CurrencyCode: currency,
+		})
+	}
+
+	info := &heleket.BalanceInfo{Merchant: merchant, User: []*heleket.WalletBalance{}}
+	return []map[string]any{{"balance": info}}, nil
+}
+
+func (s *Server) discountList(r *http.Request, body []byte) (any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	discounts := make([]*heleket.Discount, 0, len(s.paymentServices))
+	for _, service := range s.paymentServices {
+		discounts = append(discounts, &heleket.Discount{
+			Currency: service.Currency,
+			Network:  service.Network,
+			Discount: s.discounts[service.Currency+"/"+service.Network],
+		})
+	}
+	return discounts, nil
+}
+
+func (s *Server) setDiscount(r *http.Request, body []byte) (any, error) {
+	req := &heleket.SetDiscountRequest{}
+	if err := decode(body, req); err != nil {
+		return nil, err
+	}
+	if req.DiscountPercent < -99 || req.DiscountPercent > 100 {
+		return nil, invalid("discount_percent", "The discount percent must be between -99 and 100.")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.offersPayment(req.Currency, req.Network) {
+		return nil, invalid("network", "The selected network is invalid.")
+	}
+	s.discounts[req.Currency+"/"+req.Network] = req.DiscountPercent
+	return &heleket.Discount{Currency: req.Currency, Network: req.Network, Discount: req.DiscountPercent}, nil
+}
+
+func (s *Server) exchangeRates(r *http.Request, body []byte) (any, error) {
+	from := r.PathValue("currency")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.usdRate(from); !ok {
+		return nil, notFound("Currency not found")
+	}
+
+	targets := make([]string, 0, len(s.usdRates))
+	for currency := range s.usdRates {
+		if currency != from {
+			targets = append(targets, currency)
+		}
+	}
+	slices.Sort(targets)
+
+	rates := make([]*heleket.ExchangeRate, 0, len(targets))
+	for _, to := range targets {
+		course, _ := s.convert(big.NewRat(1, 1), from, to)
+		rates = append(rates, &heleket.ExchangeRate{From: from, To: to, Course: formatAmount(course)})
+	}
+	return rates, nil
+}
@@ -0,0 +1,312 @@
+package heleketfake
+
+import (
+	"encoding/base64"
+	"math/big"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/idanyas/heleket-go"
+)
+
+// invoice is a payment together with the settings Heleket keeps privately.
+type invoice struct {
+	payment     *heleket.Payment
+	urlCallback string
+}
+
+var orderIdPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,128}$`)
+
+func (s *Server) createInvoice(r *http.Request, body []byte) (any, error) {
+	req := &heleket.InvoiceRequest{}
+	if err := decode(body, req); err != nil {
+		return nil, err
+	}
+	options := req.InvoiceRequestOptions
+	if options == nil {
+		options = &heleket.InvoiceRequestOptions{}
+	}
+
+	amount, ok := parseAmount(req.Amount)
+	if !ok || amount.Sign() == 0 {
+		return nil, invalid("amount", "The amount field must be a positive number.")
+	}
+	if req.Currency == "" {
+		return nil, invalid("currency", "The currency field is required.")
+	}
+	if _, ok := s.usdRate(req.Currency); !ok {
+		return nil, invalid("currency", "The selected currency is invalid.")
+	}
+	if !orderIdPattern.MatchString(req.OrderId) {
+		return nil, invalid("order_id", "The order id may only contain letters, numbers, dashes and underscores.")
+	}
+	if options.Lifetime != 0 && (options.Lifetime < 300 || options.Lifetime > 43200) {
+		return nil, invalid("lifetime", "The lifetime must be between 300 and 43200.")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Heleket returns the existing invoice for a repeated order_id until it is final.
+	if existing := s.findInvoice("", req.OrderId); existing != nil {
+		if existing.payment.IsFinal {
+			return nil, invalid("order_id", "The order id has already been taken.")
+		}
+		return copyPayment(existing.payment), nil
+	}
+
+	payerCurrency := options.ToCurrency
+	if payerCurrency == "" {
+		if _, isCrypto := networkOf(req.Currency); isCrypto {
+			payerCurrency = req.Currency
+		}
+	}
+	network := options.Network
+	if network == "" && payerCurrency != "" {
+		network, _ = networkOf(payerCurrency)
+	}
+	if payerCurrency == "" && network != "" {
+		payerCurrency = currencyOn(network)
+	}
+
+	now := s.now()
+	lifetime := options.Lifetime
+	if lifetime == 0 {
+		lifetime = 3600
+	}
+
+	uuid := newUUID()
+	payment := &heleket.Payment{
+		UUID:            uuid,
+		OrderId:         req.OrderId,
+		Amount:          formatAmount(amount),
+		PaymentAmount:   "0.00000000",
+		DiscountPercent: options.DiscountPercent,
+		Discount:        "0.00000000",
+		Currency:        req.Currency,
+		PayerCurrency:   payerCurrency,
+		Network:         network,
+		PaymentStatus:   heleket.StatusCheck,
+		Status:          heleket.StatusCheck,
+		Url:             "https://pay.heleket.com/pay/" + uuid,
+		ExpiredAt:       float64(now.Add(time.Duration(lifetime) * time.Second).Unix()),
+		AdditionalData:  options.AdditionalData,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+
+	if payerCurrency != "" {
+		if payerAmount, ok := s.convert(amount, req.Currency, payerCurrency); ok {
+			payment.PayerAmount = formatAmount(payerAmount)
+			if rate, ok := s.convert(big.NewRat(1, 1), req.Currency, payerCurrency); ok {
+				payment.PayerAmountExchangeRate = formatAmount(rate)
+			}
+		}
+		payment.Address = depositAddress(network, uuid)
+		payment.AddressQrCode = qrCode(payment.Address)
+	}
+
+	s.invoices = append(s.invoices, &invoice{payment: payment, urlCallback: options.UrlCallback})
+	return copyPayment(payment), nil
+}
+
+func (s *Server) paymentQRCode(r *http.Request, body []byte) (any, error) {
+	var req struct {
+		UUID string `json:"merchant_payment_uuid"`
+	}
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	inv := s.findInvoice(req.UUID, "")
+	if inv == nil {
+		return nil, notFound("Payment not found")
+	}
+	if inv.payment.Address == "" {
+		return nil, invalid("merchant_payment_uuid", "The payment has no address yet.")
+	}
+	return map[string]string{"image": qrCode(inv.payment.Address)}, nil
+}
+
+func (s *Server) paymentInfo(r *http.Request, body []byte) (any, error) {
+	req := &heleket.PaymentInfoRequest{}
+	if err := decode(body, req); err != nil {
+		return nil, err
+	}
+	if req.PaymentUUID == "" && req.OrderId == "" {
+		return nil, invalid("uuid", "The uuid field is required when order id is not present.")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	inv := s.findInvoice(req.PaymentUUID, req.OrderId)
+	if inv == nil {
+		return nil, notFound("Payment not found")
+	}
+	return copyPayment(inv.payment), nil
+}
+
+func (s *Server) paymentHistory(r *http.Request, body []byte) (any, error) {
+	from, to, err := parseHistoryRange(body)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var items []*heleket.Payment
+	for _, inv := range s.invoices {
+		if inHistoryRange(inv.payment.CreatedAt, from, to) {
+			items = append(items, copyPayment(inv.payment))
+		}
+	}
+	slices.SortStableFunc(items, func(a, b *heleket.Payment) int {
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+
+	page, paginate, err := paginate(items, r.URL.Query().Get("cursor"), s.perPage)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"items": page, "paginate": paginate}, nil
+}
+
+func (s *Server) paymentServicesList(r *http.Request, body []byte) (any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.paymentServices, nil
+}
+
+// findInvoice looks an invoice up by uuid, or by order_id if uuid is empty.
+// The caller must hold s.mu.
+func (s *Server) findInvoice(uuid, orderId string) *invoice {
+	for _, inv := range s.invoices {
+		if (uuid != "" && inv.payment.UUID == uuid) || (uuid == "" && orderId != "" && inv.payment.OrderId == orderId) {
+			return inv
+		}
+	}
+	return nil
+}
+
+// networkOf returns the first network a crypto currency is offered on.
+func networkOf(currency string) (string, bool) {
+	for _, n := range networks {
+		if n.currency == currency {
+			return n.network, true
+		}
+	}
+	return "", false
+}
+
+// currencyOn picks the currency a payer on network is asked for, preferring USDT.
+func currencyOn(network string) string {
+	currency := ""
+	for _, n := range networks {
+		if n.network == network && (currency == "" || n.currency == "USDT") {
+			currency = n.currency
+		}
+	}
+	return currency
+}
+
+const historyTimeFormat = "2006-01-02 15:04:05"
+
+// parseHistoryRange reads date_from and date_to, interpreted in UTC.
+func parseHistoryRange(body []byte) (time.Time, time.Time, error) {
+	var req struct {
+		DateFrom string `json:"date_from"`
+		DateTo   string `json:"date_to"`
+	}
+	if err := decode(body, &req); err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	var from, to time.Time
+	var err error
+	if req.DateFrom != "" {
+		if from, err = time.ParseInLocation(historyTimeFormat, req.DateFrom, time.UTC); err != nil {
+			return from, to, invalid("date_from", "The date from does not match the format Y-m-d H:i:s.")
+		}
+	}
+	if req.DateTo != "" {
+		if to, err = time.ParseInLocation(historyTimeFormat, req.DateTo, time.UTC); err != nil {
+			return from, to, invalid("date_to", "The date to does not match the format Y-m-d H:i:s.")
+		}
+	}
+	return from, to, nil
+}
+
+// inHistoryRange reports whether t falls within [from, to], both inclusive at
+// second precision. A zero bound is open.
+func inHistoryRange(t, from, to time.Time) bool {
+	t = t.Truncate(time.Second)
+	return (from.IsZero() || !t.Before(from)) && (to.IsZero() || !t.After(to))
+}
+
+// paginate returns the page of items starting at the cursor offset.
+func paginate[T any](items []T, cursor string, perPage int) ([]T, map[string]any, error) {
+	offset := 0
+	if cursor != "" {
+		raw, err := base64.RawURLEncoding.DecodeString(cursor)
+		if err == nil {
+			offset, err = strconv.Atoi(string(raw))
+		}
+		if err != nil || offset < 0 || offset > len(items) {
+			return nil, nil, invalid("cursor", "The cursor is invalid.")
+		}
+	}
+
+	end := min(offset+perPage, len(items))
+	page := items[offset:end]
+	if page == nil {
+		page = []T{}
+	}
+
+	info := map[string]any{
+		"count":    len(page),
+		"hasPages": len(items) > perPage,
+		"perPage":  perPage,
+	}
+	if end < len(items) {
+		info["nextCursor"] = base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(end)))
+	}
+	if offset > 0 {
+		info["previousCursor"] = base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(max(offset-perPage, 0))))
+	}
+	return page, info, nil
+}
+
+// copyPayment returns a copy of a stored payment that handlers can return,
+// since responses are encoded after s.mu is released. The caller must hold
+// s.mu.
+func copyPayment(p *heleket.Payment) *heleket.Payment {
+	copied := *p
+	if p.Convert != nil {
+		convert := *p.Convert
+		copied.Convert = &convert
+	}
+	return &copied
+}
+
+// UpdatePayment applies update to the stored invoice with the given uuid,
+// e.g. to move it to another status. It reports whether the invoice exists.
+func (s *Server) UpdatePayment(uuid string, update func(payment *heleket.Payment)) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	inv := s.findInvoice(uuid, "")
+	if inv == nil {
+		return false
+	}
+	update(inv.payment)
+	inv.payment.UpdatedAt = s.now()
+	return true
+}
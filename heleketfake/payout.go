@@ -0,0 +1,201 @@
+package heleketfake
+
+import (
+	"math/big"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/idanyas/heleket-go"
+)
+
+// payout is a payout together with the data Heleket keeps privately.
+type payout struct {
+	payout      *heleket.Payout
+	orderId     string
+	urlCallback string
+	createdAt   time.Time
+}
+
+func (s *Server) createPayout(r *http.Request, body []byte) (any, error) {
+	req := &heleket.PayoutRequest{}
+	if err := decode(body, req); err != nil {
+		return nil, err
+	}
+	options := req.PayoutRequestOptions
+	if options == nil {
+		options = &heleket.PayoutRequestOptions{}
+	}
+
+	amount, ok := parseAmount(req.Amount)
+	if !ok || amount.Sign() == 0 {
+		return nil, invalid("amount", "The amount field must be a positive number.")
+	}
+	if !orderIdPattern.MatchString(req.OrderId) {
+		return nil, invalid("order_id", "The order id may only contain letters, numbers, dashes and underscores.")
+	}
+	if req.Address == "" {
+		return nil, invalid("address", "The address field is required.")
+	}
+
+	payerCurrency := req.Currency
+	if options.ToCurrency != "" {
+		payerCurrency = options.ToCurrency
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	service := s.findPayoutService(payerCurrency, req.Network)
+	if service == nil || !service.IsAvailable {
+		return nil, invalid("network", "The selected network is invalid.")
+	}
+	for _, p := range s.payouts {
+		if p.orderId == req.OrderId {
+			return nil, invalid("order_id", "The order id has already been taken.")
+		}
+	}
+
+	payerAmount := amount
+	if payerCurrency != req.Currency {
+		if payerAmount, ok = s.convert(amount, req.Currency, payerCurrency); !ok {
+			return nil, invalid("currency", "The selected currency is invalid.")
+		}
+	}
+	if service.Limit != nil {
+		if min, ok := parseAmount(service.Limit.MinAmount); ok && payerAmount.Cmp(min) < 0 {
+			return nil, invalid("amount", "The amount is less than the minimum "+service.Limit.MinAmount+".")
+		}
+		if max, ok := parseAmount(service.Limit.MaxAmount); ok && payerAmount.Cmp(max) > 0 {
+			return nil, invalid("amount", "The amount is greater than the maximum "+service.Limit.MaxAmount+".")
+		}
+	}
+
+	balance := s.balances[payerCurrency]
+	if balance == nil || balance.Cmp(payerAmount) < 0 {
+		return nil, &apiError{status: http.StatusBadRequest, message: "Not enough funds"}
+	}
+	balance = new(big.Rat).Sub(balance, payerAmount)
+	s.balances[payerCurrency] = balance
+
+	now := s.now()
+	p := &payout{
+		payout: &heleket.Payout{
+			UUID:          newUUID(),
+			Amount:        formatAmount(amount),
+			Currency:      req.Currency,
+			Network:       req.Network,
+			Address:       req.Address,
+			Status:        heleket.StatusProcess,
+			Balance:       formatAmount(balance),
+			PayerCurrency: payerCurrency,
+			PayerAmount:   formatAmount(payerAmount),
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		},
+		orderId:     req.OrderId,
+		urlCallback: options.UrlCallback,
+		createdAt:   now,
+	}
+	s.payouts = append(s.payouts, p)
+	return copyPayout(p.payout), nil
+}
+
+func (s *Server) payoutInfo(r *http.Request, body []byte) (any, error) {
+	req := &heleket.PayoutInfoRequest{}
+	if err := decode(body, req); err != nil {
+		return nil, err
+	}
+	if req.PayoutUUID == "" && req.OrderId == "" {
+		return nil, invalid("uuid", "The uuid field is required when order id is not present.")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p := s.findPayout(req.PayoutUUID, req.OrderId)
+	if p == nil {
+		return nil, notFound("Payout not found")
+	}
+	return copyPayout(p.payout), nil
+}
+
+func (s *Server) payoutHistory(r *http.Request, body []byte) (any, error) {
+	from, to, err := parseHistoryRange(body)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var matched []*payout
+	for _, p := range s.payouts {
+		if inHistoryRange(p.createdAt, from, to) {
+			matched = append(matched, p)
+		}
+	}
+	slices.SortStableFunc(matched, func(a, b *payout) int {
+		return b.createdAt.Compare(a.createdAt)
+	})
+
+	items := make([]*heleket.Payout, len(matched))
+	for i, p := range matched {
+		items[i] = copyPayout(p.payout)
+	}
+
+	page, paginate, err := paginate(items, r.URL.Query().Get("cursor"), s.perPage)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"items": page, "paginate": paginate}, nil
+}
+
+func (s *Server) payoutServicesList(r *http.Request, body []byte) (any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.payoutServices, nil
+}
+
+// findPayout looks a payout up by uuid, or by order_id if uuid is empty.
+// The caller must hold s.mu.
+func (s *Server) findPayout(uuid, orderId string) *payout {
+	for _, p := range s.payouts {
+		if (uuid != "" && p.payout.UUID == uuid) || (uuid == "" && orderId != "" && p.orderId == orderId) {
+			return p
+		}
+	}
+	return nil
+}
+
+// findPayoutService returns the payout service for a currency and network.
+// The caller must hold s.mu.
+func (s *Server) findPayoutService(currency, network string) *heleket.PayoutService {
+	for _, service := range s.payoutServices {
+		if service.Currency == currency && service.Network == network {
+			return service
+		}
+	}
+	return nil
+}
+
+// copyPayout returns a copy of a stored payout. See copyPayment.
+func copyPayout(p *heleket.Payout) *heleket.Payout {
+	copied := *p
+	return &copied
+}
+
+// UpdatePayout applies update to the stored payout with the given uuid.
+// It reports whether the payout exists.
+func (s *Server) UpdatePayout(uuid string, update func(payout *heleket.Payout)) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p := s.findPayout(uuid, "")
+	if p == nil {
+		return false
+	}
+	update(p.payout)
+	p.payout.UpdatedAt = s.now()
+	return true
+}
@@ -0,0 +1,61 @@
+package heleketfake
+
+import (
+	"math/big"
+	"net/http"
+
+	"github.com/idanyas/heleket-go"
+)
+
+func (s *Server) refund(r *http.Request, body []byte) (any, error) {
+	req := &heleket.RefundRequest{}
+	if err := decode(body, req); err != nil {
+		return nil, err
+	}
+	if req.Address == "" {
+		return nil, invalid("address", "The address field is required.")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	inv := s.findInvoice(req.PaymentUUID, req.OrderId)
+	if inv == nil {
+		return nil, notFound("Payment not found")
+	}
+	switch inv.payment.PaymentStatus {
+	case heleket.StatusPaid, heleket.StatusPaidOver:
+	default:
+		return nil, &apiError{status: http.StatusUnprocessableEntity, message: "The payment can not be refunded"}
+	}
+
+	inv.payment.PaymentStatus = heleket.StatusRefundProcess
+	inv.payment.Status = heleket.StatusRefundProcess
+	inv.payment.UpdatedAt = s.now()
+	return []string{}, nil
+}
+
+func (s *Server) blockedAddressRefund(r *http.Request, body []byte) (any, error) {
+	req := &heleket.BlockedAddressRefundRequest{}
+	if err := decode(body, req); err != nil {
+		return nil, err
+	}
+	if req.Address == "" {
+		return nil, invalid("address", "The address field is required.")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	w := s.findWallet(req.WalletUUID, req.OrderId)
+	if w == nil {
+		return nil, notFound("Wallet not found")
+	}
+	if !w.blocked {
+		return nil, &apiError{status: http.StatusUnprocessableEntity, message: "The wallet address is not blocked"}
+	}
+
+	amount := w.received
+	w.received = new(big.Rat)
+	return &heleket.BlockedAddressRefundResponse{Commission: "0.00000000", Amount: formatAmount(amount)}, nil
+}
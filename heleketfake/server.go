@@ -0,0 +1,296 @@
+// Package heleketfake runs an in-process fake of the Heleket API for tests.
+//
+// The fake implements every endpoint the SDK calls, checks the merchant and
+// sign headers the way Heleket does and keeps invoices, payouts, static
+// wallets, balances and discounts in memory so they can be queried back:
+//
+//	fake := heleketfake.New()
+//	defer fake.Close()
+//
+//	client := fake.Client()
+//	invoice, err := client.CreateInvoice(&heleket.InvoiceRequest{...})
+package heleketfake
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/idanyas/heleket-go"
+)
+
+// Default credentials accepted by a fake created without WithCredentials.
+const (
+	DefaultMerchant   = "fake-merchant"
+	DefaultPaymentKey = "fake-payment-key"
+	DefaultPayoutKey  = "fake-payout-key"
+)
+
+// Server is a fake Heleket API backed by an httptest.Server.
+type Server struct {
+	merchant   string
+	paymentKey string
+	payoutKey  string
+	now        func() time.Time
+	perPage    int
+
+	httpServer *httptest.Server
+
+	mu              sync.Mutex
+	invoices        []*invoice
+	payouts         []*payout
+	wallets         []*wallet
+	balances        map[string]*big.Rat
+	paymentServices []*heleket.PaymentService
+	payoutServices  []*heleket.PayoutService
+	discounts       map[string]int8
+	usdRates        map[string]*big.Rat
+}
+
+// Option configures a Server created by New.
+type Option func(*Server)
+
+// WithCredentials sets the merchant id and API keys the fake accepts.
+func WithCredentials(merchant, paymentKey, payoutKey string) Option {
+	return func(s *Server) {
+		s.merchant = merchant
+		s.paymentKey = paymentKey
+		s.payoutKey = payoutKey
+	}
+}
+
+// WithClock sets the clock used for timestamps and invoice expiry.
+func WithClock(now func() time.Time) Option {
+	return func(s *Server) {
+		s.now = now
+	}
+}
+
+// WithBalance sets the merchant balance of a currency, e.g. ("USDT", "100").
+func WithBalance(currency, amount string) Option {
+	return func(s *Server) {
+		if value, ok := parseAmount(amount); ok {
+			s.balances[currency] = value
+		}
+	}
+}
+
+// WithPerPage sets the page size of history endpoints. Defaults to 15.
+func WithPerPage(perPage int) Option {
+	return func(s *Server) {
+		s.perPage = perPage
+	}
+}
+
+// WithPaymentServices replaces the payment services the fake offers.
+func WithPaymentServices(services []*heleket.PaymentService) Option {
+	return func(s *Server) {
+		s.paymentServices = services
+	}
+}
+
+// WithPayoutServices replaces the payout services the fake offers.
+func WithPayoutServices(services []*heleket.PayoutService) Option {
+	return func(s *Server) {
+		s.payoutServices = services
+	}
+}
+
+// New starts a fake Heleket API. Call Close when done.
+func New(opts ...Option) *Server {
+	s := &Server{
+		merchant:        DefaultMerchant,
+		paymentKey:      DefaultPaymentKey,
+		payoutKey:       DefaultPayoutKey,
+		now:             time.Now,
+		perPage:         15,
+		balances:        defaultBalances(),
+		paymentServices: defaultPaymentServices(),
+		payoutServices:  defaultPayoutServices(),
+		discounts:       make(map[string]int8),
+		usdRates:        defaultUSDRates(),
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.httpServer = httptest.NewServer(s.routes())
+	return s
+}
+
+// URL returns the base URL to pass to heleket.WithBaseURL.
+func (s *Server) URL() string {
+	return s.httpServer.URL + "/v1"
+}
+
+// Client returns a Heleket client configured for the fake.
+func (s *Server) Client(opts ...heleket.Option) *heleket.Heleket {
+	opts = append([]heleket.Option{heleket.WithBaseURL(s.URL())}, opts...)
+	return heleket.New(s.httpServer.Client(), s.merchant, s.paymentKey, s.payoutKey, opts...)
+}
+
+// Close shuts the fake down.
+func (s *Server) Close() {
+	s.httpServer.Close()
+}
+
+// Now returns the current time of the fake's clock.
+func (s *Server) Now() time.Time {
+	return s.now()
+}
+
+func (s *Server) routes() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.Handle("POST /v1/payment", s.handle(heleket.PaymentKey, s.createInvoice))
+	mux.Handle("POST /v1/payment/qr", s.handle(heleket.PaymentKey, s.paymentQRCode))
+	mux.Handle("POST /v1/payment/info", s.handle(heleket.PaymentKey, s.paymentInfo))
+	mux.Handle("POST /v1/payment/list", s.handle(heleket.PaymentKey, s.paymentHistory))
+	mux.Handle("POST /v1/payment/services", s.handle(heleket.PaymentKey, s.paymentServicesList))
+
+	mux.Handle("POST /v1/payout", s.handle(heleket.PayoutKey, s.createPayout))
+	mux.Handle("POST /v1/payout/info", s.handle(heleket.PayoutKey, s.payoutInfo))
+	mux.Handle("POST /v1/payout/list", s.handle(heleket.PayoutKey, s.payoutHistory))
+	mux.Handle("POST /v1/payout/services", s.handle(heleket.PayoutKey, s.payoutServicesList))
+
+	mux.Handle("POST /v1/wallet", s.handle(heleket.PaymentKey, s.createStaticWallet))
+	mux.Handle("POST /v1/wallet/qr", s.handle(heleket.PaymentKey, s.staticWalletQRCode))
+	mux.Handle("POST /v1/wallet/block-address", s.handle(heleket.PaymentKey, s.blockAddress))
+	mux.Handle("POST /v1/wallet/blocked-address-refund", s.handle(heleket.PaymentKey, s.blockedAddressRefund))
+
+	mux.Handle("POST /v1/payment/refund", s.handle(heleket.PaymentKey, s.refund))
+
+	mux.Handle("POST /v1/balance", s.handle(heleket.PaymentKey, s.balance))
+	mux.Handle("POST /v1/payment/discount/list", s.handle(heleket.PaymentKey, s.discountList))
+	mux.Handle("POST /v1/payment/discount/set", s.handle(heleket.PaymentKey, s.setDiscount))
+	mux.Handle("GET /v1/exchange-rate/{currency}/list", s.handle(heleket.PaymentKey, s.exchangeRates))
+
+	mux.Handle("POST /v1/payment/resend", s.handle(heleket.PaymentKey, s.resendWebhook))
+	mux.Handle("POST /v1/test-webhook/payment", s.handle(heleket.PaymentKey, s.testWebhook))
+	mux.Handle("POST /v1/test-webhook/payout", s.handle(heleket.PayoutKey, s.testWebhook))
+	mux.Handle("POST /v1/test-webhook/wallet", s.handle(heleket.PaymentKey, s.testWebhook))
+
+	return mux
+}
+
+// apiError is a failure rendered the way Heleket renders it.
+type apiError struct {
+	status  int
+	message string
+	errors  map[string][]string
+}
+
+func (e *apiError) Error() string {
+	return e.message
+}
+
+func notFound(message string) *apiError {
+	return &apiError{status: http.StatusNotFound, message: message}
+}
+
+func invalid(field, message string) *apiError {
+	return &apiError{status: http.StatusUnprocessableEntity, errors: map[string][]string{field: {message}}}
+}
+
+type handlerFunc func(r *http.Request, body []byte) (any, error)
+
+// handle authenticates the request and renders the result envelope.
+func (s *Server) handle(keyKind heleket.KeyKind, h handlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"state": 1, "message": err.Error()})
+			return
+		}
+
+		key := s.paymentKey
+		if keyKind == heleket.PayoutKey {
+			key = s.payoutKey
+		}
+		if r.Header.Get("merchant") != s.merchant || !validSign(r.Header.Get("sign"), body, key) {
+			writeJSON(w, http.StatusUnauthorized, map[string]any{"state": 1, "message": "Invalid Sign"})
+			return
+		}
+
+		result, err := h(r, body)
+		if err != nil {
+			apiErr, ok := err.(*apiError)
+			if !ok {
+				apiErr = &apiError{status: http.StatusUnprocessableEntity, message: err.Error()}
+			}
+			payload := map[string]any{"state": 1}
+			if apiErr.message != "" {
+				payload["message"] = apiErr.message
+			}
+			if len(apiErr.errors) > 0 {
+				payload["errors"] = apiErr.errors
+			}
+			writeJSON(w, apiErr.status, payload)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, map[string]any{"state": 0, "result": result})
+	})
+}
+
+// Sign computes a signature with Heleket's algorithm, MD5(base64(body) + apiKey).
+func Sign(body []byte, apiKey string) string {
+	hash := md5.Sum([]byte(base64.StdEncoding.EncodeToString(body) + apiKey))
+	return hex.EncodeToString(hash[:])
+}
+
+func validSign(sign string, body []byte, apiKey string) bool {
+	return subtle.ConstantTimeCompare([]byte(sign), []byte(Sign(body, apiKey))) == 1
+}
+
+func writeJSON(w http.ResponseWriter, status int, payload any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(payload)
+}
+
+func decode(body []byte, v any) error {
+	if err := json.Unmarshal(body, v); err != nil {
+		return &apiError{status: http.StatusUnprocessableEntity, message: "Invalid JSON: " + err.Error()}
+	}
+	return nil
+}
+
+func newUUID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+func parseAmount(s string) (*big.Rat, bool) {
+	if s == "" {
+		return nil, false
+	}
+	value, ok := new(big.Rat).SetString(s)
+	if !ok || value.Sign() < 0 {
+		return nil, false
+	}
+	return value, true
+}
+
+func formatAmount(value *big.Rat) string {
+	return value.FloatString(8)
+}
+
+// qrCode returns a placeholder PNG data URI.
+func qrCode(content string) string {
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString([]byte(content))
+}
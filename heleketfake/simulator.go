@@ -0,0 +1,380 @@
+package heleketfake
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/idanyas/heleket-go"
+)
+
+// Clock is a manually advanced clock for driving a Server and a Simulator.
+type Clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewClock returns a clock stopped at start.
+func NewClock(start time.Time) *Clock {
+	return &Clock{now: start}
+}
+
+// Now returns the clock's current time. Pass it to WithClock.
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *Clock) set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if t.After(c.now) {
+		c.now = t
+	}
+}
+
+// Step moves an invoice to Status once After has elapsed since the script started.
+type Step struct {
+	After  time.Duration
+	Status heleket.Status
+}
+
+// Script is a sequence of steps an invoice goes through.
+type Script []Step
+
+// Scripts for the outcomes Heleket reports in webhooks.
+var (
+	ScriptPaid = Script{
+		{0, heleket.StatusCheck},
+		{time.Minute, heleket.StatusConfirmCheck},
+		{5 * time.Minute, heleket.StatusPaid},
+	}
+	ScriptPaidOver = Script{
+		{0, heleket.StatusCheck},
+		{time.Minute, heleket.StatusConfirmCheck},
+		{5 * time.Minute, heleket.StatusPaidOver},
+	}
+	ScriptWrongAmount = Script{
+		{0, heleket.StatusCheck},
+		{time.Minute, heleket.StatusConfirmCheck},
+		{5 * time.Minute, heleket.StatusWrongAmount},
+	}
+	ScriptCancel = Script{
+		{0, heleket.StatusCheck},
+		{time.Hour, heleket.StatusCancel},
+	}
+	ScriptFail = Script{
+		{0, heleket.StatusCheck},
+		{time.Minute, heleket.StatusConfirmCheck},
+		{5 * time.Minute, heleket.StatusFail},
+	}
+	ScriptRefund = Script{
+		{0, heleket.StatusCheck},
+		{time.Minute, heleket.StatusConfirmCheck},
+		{5 * time.Minute, heleket.StatusPaid},
+		{time.Hour, heleket.StatusRefundProcess},
+		{2 * time.Hour, heleket.StatusRefundPaid},
+	}
+)
+
+// Delivery is a webhook the simulator sent.
+type Delivery struct {
+	URL        string
+	Body       []byte
+	Status     heleket.Status
+	StatusCode int
+	Err        error
+}
+
+// Simulator moves invoices of a Server through scripted status sequences and
+// posts signed webhooks to their url_callback, the way Heleket does. Time
+// only passes when Advance is called.
+type Simulator struct {
+	server *Server
+	clock  *Clock
+	client *http.Client
+
+	mu      sync.Mutex
+	pending []scheduledStep
+}
+
+type scheduledStep struct {
+	at     time.Time
+	seq    int
+	uuid   string
+	status heleket.Status
+}
+
+// NewSimulator returns a simulator for server. The server must have been
+// created with WithClock(clock.Now).
+func NewSimulator(server *Server, clock *Clock) *Simulator {
+	return &Simulator{server: server, clock: clock, client: http.DefaultClient}
+}
+
+// SetHTTPClient sets the client used to deliver webhooks.
+func (s *Simulator) SetHTTPClient(client *http.Client) {
+	s.client = client
+}
+
+// Run schedules script for the invoice with the given uuid, starting now.
+// Nothing is scheduled if any step is invalid.
+func (s *Simulator) Run(uuid string, script Script) error {
+	for _, step := range script {
+		if !knownStatuses[step.Status] {
+			return fmt.Errorf("heleketfake: unknown status %q", step.Status)
+		}
+	}
+
+	s.server.mu.Lock()
+	exists := s.server.findInvoice(uuid, "") != nil
+	s.server.mu.Unlock()
+	if !exists {
+		return fmt.Errorf("heleketfake: invoice %s not found", uuid)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	start := s.clock.Now()
+	for _, step := range script {
+		s.pending = append(s.pending, scheduledStep{at: start.Add(step.After), seq: len(s.pending), uuid: uuid, status: step.Status})
+	}
+	return nil
+}
+
+// Advance moves the clock forward by d, applying every step that falls due
+// in order and delivering the resulting webhooks synchronously.
+func (s *Simulator) Advance(ctx context.Context, d time.Duration) []Delivery {
+	target := s.clock.Now().Add(d)
+
+	s.mu.Lock()
+	slices.SortStableFunc(s.pending, func(a, b scheduledStep) int {
+		if c := a.at.Compare(b.at); c != 0 {
+			return c
+		}
+		return a.seq - b.seq
+	})
+	var due []scheduledStep
+	for len(s.pending) > 0 && !s.pending[0].at.After(target) {
+		due = append(due, s.pending[0])
+		s.pending = s.pending[1:]
+	}
+	s.mu.Unlock()
+
+	var deliveries []Delivery
+	for _, step := range due {
+		s.clock.set(step.at)
+		if delivery, ok := s.apply(ctx, step); ok {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	s.clock.set(target)
+	return deliveries
+}
+
+// Pending returns the number of steps still scheduled.
+func (s *Simulator) Pending() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.pending)
+}
+
+var knownStatuses = map[heleket.Status]bool{
+	heleket.StatusCheck:         true,
+	heleket.StatusConfirmCheck:  true,
+	heleket.StatusPaid:          true,
+	heleket.StatusPaidOver:      true,
+	heleket.StatusWrongAmount:   true,
+	heleket.StatusCancel:        true,
+	heleket.StatusFail:          true,
+	heleket.StatusRefundProcess: true,
+	heleket.StatusRefundPaid:    true,
+}
+
+// apply moves the invoice to the step's status and sends a webhook for
+// every status but "check", which Heleket does not report.
+func (s *Simulator) apply(ctx context.Context, step scheduledStep) (Delivery, bool) {
+	srv := s.server
+	srv.mu.Lock()
+	inv := srv.findInvoice(step.uuid, "")
+	if inv == nil {
+		srv.mu.Unlock()
+		return Delivery{}, false
+	}
+	srv.transition(inv, step.status)
+	body, err := srv.paymentWebhook(inv)
+	callback := inv.urlCallback
+	srv.mu.Unlock()
+
+	if step.status == heleket.StatusCheck || callback == "" {
+		return Delivery{}, false
+	}
+
+	delivery := Delivery{URL: callback, Body: body, Status: step.status, Err: err}
+	if err == nil {
+		delivery.StatusCode, delivery.Err = s.post(ctx, callback, body)
+	}
+	return delivery, true
+}
+
+func (s *Simulator) post(ctx context.Context, url string, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	io.Copy(io.Discard, res.Body)
+	return res.StatusCode, nil
+}
+
+// merchantCommissionPercent is the fee the fake keeps from paid invoices.
+var merchantCommissionPercent = big.NewRat(2, 100)
+
+// transition updates the payment fields that change with status.
+// The caller must hold s.mu.
+func (s *Server) transition(inv *invoice, status heleket.Status) {
+	p := inv.payment
+	p.PaymentStatus = status
+	p.Status = status
+	p.UpdatedAt = s.now()
+
+	expected, ok := parseAmount(p.PayerAmount)
+	if !ok {
+		expected, _ = parseAmount(p.Amount)
+	}
+
+	var paid *big.Rat
+	switch status {
+	case heleket.StatusCheck:
+		p.IsFinal = false
+		return
+	case heleket.StatusCancel, heleket.StatusFail:
+		p.IsFinal = true
+		return
+	case heleket.StatusConfirmCheck, heleket.StatusPaid:
+		paid = expected
+	case heleket.StatusPaidOver:
+		paid = new(big.Rat).Mul(expected, big.NewRat(11, 10))
+	case heleket.StatusWrongAmount:
+		paid = new(big.Rat).Mul(expected, big.NewRat(1, 2))
+	case heleket.StatusRefundProcess, heleket.StatusRefundPaid:
+		p.IsFinal = true
+		return
+	}
+
+	p.IsFinal = status != heleket.StatusConfirmCheck
+	p.PaymentAmount = formatAmount(paid)
+	if p.TxId == "" {
+		p.TxId = Sign([]byte(p.UUID), "txid") + Sign([]byte(p.UUID), "txid2")
+		p.From = depositAddress(p.Network, "payer-"+p.UUID)
+	}
+	currency := p.PayerCurrency
+	if currency == "" {
+		currency = p.Currency
+	}
+	if usd, ok := s.convert(paid, currency, "USD"); ok {
+		p.PaymentAmountUSD = usd.FloatString(2)
+	}
+
+	if p.IsFinal {
+		commission := new(big.Rat).Mul(paid, merchantCommissionPercent)
+		merchantAmount := new(big.Rat).Sub(paid, commission)
+		p.Commission = formatAmount(commission)
+		p.MerchantAmount = formatAmount(merchantAmount)
+
+		balance := s.balances[currency]
+		if balance == nil {
+			balance = new(big.Rat)
+		}
+		s.balances[currency] = new(big.Rat).Add(balance, merchantAmount)
+	}
+}
+
+// paymentWebhook is the webhook body in the field order Heleket uses.
+type paymentWebhook struct {
+	Type              heleket.WebhookType `json:"type"`
+	UUID              string              `json:"uuid"`
+	OrderId           string              `json:"order_id"`
+	Amount            string              `json:"amount"`
+	PaymentAmount     string              `json:"payment_amount"`
+	PaymentAmountUSD  string              `json:"payment_amount_usd"`
+	MerchantAmount    string              `json:"merchant_amount"`
+	Commission        string              `json:"commission"`
+	IsFinal           bool                `json:"is_final"`
+	Status            heleket.Status      `json:"status"`
+	From              *string             `json:"from"`
+	WalletAddressUUID *string             `json:"wallet_address_uuid"`
+	Network           string              `json:"network"`
+	Currency          string              `json:"currency"`
+	PayerCurrency     string              `json:"payer_currency"`
+	AdditionalData    *string             `json:"additional_data"`
+	TxId              *string             `json:"txid"`
+}
+
+// paymentWebhook renders and signs the webhook for the invoice's current state.
+// The caller must hold s.mu.
+func (s *Server) paymentWebhook(inv *invoice) ([]byte, error) {
+	p := inv.payment
+	webhook := paymentWebhook{
+		Type:             heleket.WebhookTypePayment,
+		UUID:             p.UUID,
+		OrderId:          p.OrderId,
+		Amount:           p.Amount,
+		PaymentAmount:    p.PaymentAmount,
+		PaymentAmountUSD: p.PaymentAmountUSD,
+		MerchantAmount:   p.MerchantAmount,
+		Commission:       p.Commission,
+		IsFinal:          p.IsFinal,
+		Status:           p.PaymentStatus,
+		From:             nullable(p.From),
+		Network:          p.Network,
+		Currency:         p.Currency,
+		PayerCurrency:    p.PayerCurrency,
+		AdditionalData:   nullable(p.AdditionalData),
+		TxId:             nullable(p.TxId),
+	}
+
+	body, err := json.Marshal(webhook)
+	if err != nil {
+		return nil, err
+	}
+	return SignWebhook(body, s.paymentKey)
+}
+
+// SignWebhook adds a "sign" field to a JSON object body, computed over the
+// body as given with Heleket's algorithm.
+func SignWebhook(body []byte, apiKey string) ([]byte, error) {
+	body = bytes.TrimSpace(body)
+	if len(body) < 2 || body[0] != '{' || body[len(body)-1] != '}' {
+		return nil, errors.New("heleketfake: webhook body must be a JSON object")
+	}
+
+	signed := append([]byte(nil), body[:len(body)-1]...)
+	if len(bytes.TrimSpace(body[1:len(body)-1])) > 0 {
+		signed = append(signed, ',')
+	}
+	signed = append(signed, `"sign":"`+Sign(body, apiKey)+`"}`...)
+	return signed, nil
+}
+
+func nullable(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
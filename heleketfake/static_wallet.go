@@ -0,0 +1,120 @@
+package heleketfake
+
+import (
+	"math/big"
+	"net/http"
+
+	"github.com/idanyas/heleket-go"
+)
+
+// wallet is a static wallet together with the data Heleket keeps privately.
+type wallet struct {
+	wallet      *heleket.StaticWalletResponse
+	urlCallback string
+	blocked     bool
+	received    *big.Rat
+}
+
+func (s *Server) createStaticWallet(r *http.Request, body []byte) (any, error) {
+	req := &heleket.StaticWalletRequest{}
+	if err := decode(body, req); err != nil {
+		return nil, err
+	}
+	options := req.StaticWalletRequestOptions
+	if options == nil {
+		options = &heleket.StaticWalletRequestOptions{}
+	}
+
+	if !orderIdPattern.MatchString(req.OrderId) {
+		return nil, invalid("order_id", "The order id may only contain letters, numbers, dashes and underscores.")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.offersPayment(req.Currency, req.Network) {
+		return nil, invalid("network", "The selected network is invalid.")
+	}
+
+	// A repeated order_id on the same currency and network returns the same wallet.
+	for _, w := range s.wallets {
+		if w.wallet.OrderId == req.OrderId && w.wallet.Currency == req.Currency && w.wallet.Network == req.Network {
+			return w.wallet, nil
+		}
+	}
+
+	uuid := newUUID()
+	w := &wallet{
+		wallet: &heleket.StaticWalletResponse{
+			OrderId:    req.OrderId,
+			WalletUUID: newUUID(),
+			UUID:       uuid,
+			Address:    depositAddress(req.Network, uuid),
+			Network:    req.Network,
+			Currency:   req.Currency,
+			Url:        "https://pay.heleket.com/wallet/" + uuid,
+		},
+		urlCallback: options.UrlCallback,
+		received:    new(big.Rat),
+	}
+	s.wallets = append(s.wallets, w)
+	return w.wallet, nil
+}
+
+func (s *Server) staticWalletQRCode(r *http.Request, body []byte) (any, error) {
+	var req struct {
+		UUID string `json:"wallet_address_uuid"`
+	}
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	w := s.findWallet(req.UUID, "")
+	if w == nil {
+		return nil, notFound("Wallet not found")
+	}
+	return map[string]string{"image": qrCode(w.wallet.Address)}, nil
+}
+
+func (s *Server) blockAddress(r *http.Request, body []byte) (any, error) {
+	req := &heleket.BlockAddressRequest{}
+	if err := decode(body, req); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	w := s.findWallet(req.WalletUUID, req.OrderId)
+	if w == nil {
+		return nil, notFound("Wallet not found")
+	}
+	w.blocked = true
+	return &heleket.BlockAddressResponse{WalletUUID: w.wallet.UUID, Status: "blocked"}, nil
+}
+
+// findWallet looks a wallet up by uuid, or by order_id if uuid is empty.
+// The caller must hold s.mu.
+func (s *Server) findWallet(uuid, orderId string) *wallet {
+	for _, w := range s.wallets {
+		if (uuid != "" && (w.wallet.UUID == uuid || w.wallet.WalletUUID == uuid)) ||
+			(uuid == "" && orderId != "" && w.wallet.OrderId == orderId) {
+			return w
+		}
+	}
+	return nil
+}
+
+// offersPayment reports whether payments in currency on network are accepted.
+// The caller must hold s.mu.
+func (s *Server) offersPayment(currency, network string) bool {
+	for _, service := range s.paymentServices {
+		if service.Currency == currency && service.Network == network {
+			return service.IsAvailable
+		}
+	}
+	return false
+}
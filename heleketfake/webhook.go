@@ -0,0 +1,37 @@
+package heleketfake
+
+import (
+	"net/http"
+
+	"github.com/idanyas/heleket-go"
+)
+
+func (s *Server) resendWebhook(r *http.Request, body []byte) (any, error) {
+	req := &heleket.ResendWebhookRequest{}
+	if err := decode(body, req); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	inv := s.findInvoice(req.PaymentUUID, req.OrderId)
+	if inv == nil {
+		return nil, notFound("Payment not found")
+	}
+	if inv.urlCallback == "" {
+		return nil, invalid("url_callback", "The payment has no callback url.")
+	}
+	return []string{}, nil
+}
+
+func (s *Server) testWebhook(r *http.Request, body []byte) (any, error) {
+	req := &heleket.TestWebhookRequest{}
+	if err := decode(body, req); err != nil {
+		return nil, err
+	}
+	if req.UrlCallback == "" {
+		return nil, invalid("url_callback", "The url callback field is required.")
+	}
+	return []string{}, nil
+}
@@ -0,0 +1,429 @@
+// Package heleketmock provides a mock heleket.Client for unit tests.
+//
+// Stub a method by setting its Func field; calls to methods without a stub
+// return ErrNotStubbed. Every call, stubbed or not, is recorded:
+//
+//	mock := &heleketmock.Client{
+//		CreateInvoiceFunc: func(ctx context.Context, req *heleket.InvoiceRequest) (*heleket.Payment, error) {
+//			return &heleket.Payment{UUID: "uuid", OrderId: req.OrderId}, nil
+//		},
+//	}
+//	checkout := NewCheckout(mock)
+//	// ...
+//	calls := mock.CallsTo("CreateInvoice")
+//
+// The plain and Context variants of a method share one stub and are both
+// recorded under the plain method name.
+package heleketmock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/idanyas/heleket-go"
+)
+
+// ErrNotStubbed is returned by methods whose Func field is not set.
+var ErrNotStubbed = errors.New("heleketmock: method not stubbed")
+
+// Call is a recorded method call. Args holds the arguments after the context.
+type Call struct {
+	Method string
+	Ctx    context.Context
+	Args   []any
+}
+
+// Client is a mock heleket.Client.
+type Client struct {
+	// PaymentsAPI
+	CreateInvoiceFunc          func(ctx context.Context, invoiceReq *heleket.InvoiceRequest) (*heleket.Payment, error)
+	GeneratePaymentQRCodeFunc  func(ctx context.Context, paymentUUID string) (string, error)
+	GetPaymentInfoFunc         func(ctx context.Context, paymentInfoReq *heleket.PaymentInfoRequest) (*heleket.Payment, error)
+	GetPaymentHistoryFunc      func(ctx context.Context, dateFrom time.Time, dateTo time.Time, cursor string) (*heleket.PaymentHistoryResponse, error)
+	GetPaymentServicesListFunc func(ctx context.Context) ([]*heleket.PaymentService, error)
+	RefundFunc                 func(ctx context.Context, refundRequest *heleket.RefundRequest) (bool, error)
+
+	// PayoutsAPI
+	CreatePayoutFunc          func(ctx context.Context, payoutReq *heleket.PayoutRequest) (*heleket.Payout, error)
+	GetPayoutInfoFunc         func(ctx context.Context, payoutInfoReq *heleket.PayoutInfoRequest) (*heleket.Payout, error)
+	GetPayoutHistoryFunc      func(ctx context.Context, dateFrom time.Time, dateTo time.Time, cursor string) (*heleket.PayoutHistoryResponse, error)
+	GetPayoutServicesListFunc func(ctx context.Context) ([]*heleket.PayoutService, error)
+
+	// WalletsAPI
+	CreateStaticWalletFunc         func(ctx context.Context, staticWalletReq *heleket.StaticWalletRequest) (*heleket.StaticWalletResponse, error)
+	GenerateStaticWalletQRCodeFunc func(ctx context.Context, walletUUID string) (string, error)
+	BlockAddressFunc               func(ctx context.Context, blockAddressReq *heleket.BlockAddressRequest) (*heleket.BlockAddressResponse, error)
+	BlockedAddressRefundFunc       func(ctx context.Context, refundRequest *heleket.BlockedAddressRefundRequest) (*heleket.BlockedAddressRefundResponse, error)
+
+	// WebhooksAPI
+	ParseWebhookFunc       func(ctx context.Context, reqBody []byte, verifySign bool) (*heleket.Webhook, error)
+	VerifySignFunc         func(ctx context.Context, apiKey string, reqBody []byte) error
+	ResendWebhookFunc      func(ctx context.Context, resendRequest *heleket.ResendWebhookRequest) (bool, error)
+	TestPaymentWebhookFunc func(ctx context.Context, testRequest *heleket.TestWebhookRequest) (*heleket.TestWebhookResponse, error)
+	TestPayoutWebhookFunc  func(ctx context.Context, testRequest *heleket.TestWebhookRequest) (*heleket.TestWebhookResponse, error)
+	TestWalletWebhookFunc  func(ctx context.Context, testRequest *heleket.TestWebhookRequest) (*heleket.TestWebhookResponse, error)
+
+	// AccountAPI
+	GetBalanceFunc       func(ctx context.Context) (*heleket.BalanceInfo, error)
+	GetDiscountsListFunc func(ctx context.Context) ([]*heleket.Discount, error)
+	SetDiscountFunc      func(ctx context.Context, req *heleket.SetDiscountRequest) (*heleket.Discount, error)
+	GetExchangeRatesFunc func(ctx context.Context, currency string) ([]*heleket.ExchangeRate, error)
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+var _ heleket.Client = (*Client)(nil)
+
+// Calls returns every recorded call in order.
+func (m *Client) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Call(nil), m.calls...)
+}
+
+// CallsTo returns the recorded calls to method, e.g. "CreateInvoice".
+func (m *Client) CallsTo(method string) []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var calls []Call
+	for _, call := range m.calls {
+		if call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// Reset forgets all recorded calls. Stubs are kept.
+func (m *Client) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = nil
+}
+
+func (m *Client) record(ctx context.Context, method string, args ...any) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, Call{Method: method, Ctx: ctx, Args: args})
+}
+
+func notStubbed(method string) error {
+	return fmt.Errorf("%w: %s", ErrNotStubbed, method)
+}
+
+func (m *Client) CreateInvoice(invoiceReq *heleket.InvoiceRequest) (*heleket.Payment, error) {
+	return m.CreateInvoiceContext(context.Background(), invoiceReq)
+}
+
+func (m *Client) CreateInvoiceContext(ctx context.Context, invoiceReq *heleket.InvoiceRequest) (*heleket.Payment, error) {
+	m.record(ctx, "CreateInvoice", invoiceReq)
+	if m.CreateInvoiceFunc == nil {
+		err := notStubbed("CreateInvoice")
+		return nil, err
+	}
+	return m.CreateInvoiceFunc(ctx, invoiceReq)
+}
+
+func (m *Client) GeneratePaymentQRCode(paymentUUID string) (string, error) {
+	return m.GeneratePaymentQRCodeContext(context.Background(), paymentUUID)
+}
+
+func (m *Client) GeneratePaymentQRCodeContext(ctx context.Context, paymentUUID string) (string, error) {
+	m.record(ctx, "GeneratePaymentQRCode", paymentUUID)
+	if m.GeneratePaymentQRCodeFunc == nil {
+		err := notStubbed("GeneratePaymentQRCode")
+		return "", err
+	}
+	return m.GeneratePaymentQRCodeFunc(ctx, paymentUUID)
+}
+
+func (m *Client) GetPaymentInfo(paymentInfoReq *heleket.PaymentInfoRequest) (*heleket.Payment, error) {
+	return m.GetPaymentInfoContext(context.Background(), paymentInfoReq)
+}
+
+func (m *Client) GetPaymentInfoContext(ctx context.Context, paymentInfoReq *heleket.PaymentInfoRequest) (*heleket.Payment, error) {
+	m.record(ctx, "GetPaymentInfo", paymentInfoReq)
+	if m.GetPaymentInfoFunc == nil {
+		err := notStubbed("GetPaymentInfo")
+		return nil, err
+	}
+	return m.GetPaymentInfoFunc(ctx, paymentInfoReq)
+}
+
+func (m *Client) GetPaymentHistory(dateFrom time.Time, dateTo time.Time, cursor string) (*heleket.PaymentHistoryResponse, error) {
+	return m.GetPaymentHistoryContext(context.Background(), dateFrom, dateTo, cursor)
+}
+
+func (m *Client) GetPaymentHistoryContext(ctx context.Context, dateFrom time.Time, dateTo time.Time, cursor string) (*heleket.PaymentHistoryResponse, error) {
+	m.record(ctx, "GetPaymentHistory", dateFrom, dateTo, cursor)
+	if m.GetPaymentHistoryFunc == nil {
+		err := notStubbed("GetPaymentHistory")
+		return nil, err
+	}
+	return m.GetPaymentHistoryFunc(ctx, dateFrom, dateTo, cursor)
+}
+
+func (m *Client) GetPaymentServicesList() ([]*heleket.PaymentService, error) {
+	return m.GetPaymentServicesListContext(context.Background())
+}
+
+func (m *Client) GetPaymentServicesListContext(ctx context.Context) ([]*heleket.PaymentService, error) {
+	m.record(ctx, "GetPaymentServicesList")
+	if m.GetPaymentServicesListFunc == nil {
+		err := notStubbed("GetPaymentServicesList")
+		return nil, err
+	}
+	return m.GetPaymentServicesListFunc(ctx)
+}
+
+func (m *Client) Refund(refundRequest *heleket.RefundRequest) (bool, error) {
+	return m.RefundContext(context.Background(), refundRequest)
+}
+
+func (m *Client) RefundContext(ctx context.Context, refundRequest *heleket.RefundRequest) (bool, error) {
+	m.record(ctx, "Refund", refundRequest)
+	if m.RefundFunc == nil {
+		err := notStubbed("Refund")
+		return false, err
+	}
+	return m.RefundFunc(ctx, refundRequest)
+}
+
+func (m *Client) CreatePayout(payoutReq *heleket.PayoutRequest) (*heleket.Payout, error) {
+	return m.CreatePayoutContext(context.Background(), payoutReq)
+}
+
+func (m *Client) CreatePayoutContext(ctx context.Context, payoutReq *heleket.PayoutRequest) (*heleket.Payout, error) {
+	m.record(ctx, "CreatePayout", payoutReq)
+	if m.CreatePayoutFunc == nil {
+		err := notStubbed("CreatePayout")
+		return nil, err
+	}
+	return m.CreatePayoutFunc(ctx, payoutReq)
+}
+
+func (m *Client) GetPayoutInfo(payoutInfoReq *heleket.PayoutInfoRequest) (*heleket.Payout, error) {
+	return m.GetPayoutInfoContext(context.Background(), payoutInfoReq)
+}
+
+func (m *Client) GetPayoutInfoContext(ctx context.Context, payoutInfoReq *heleket.PayoutInfoRequest) (*heleket.Payout, error) {
+	m.record(ctx, "GetPayoutInfo", payoutInfoReq)
+	if m.GetPayoutInfoFunc == nil {
+		err := notStubbed("GetPayoutInfo")
+		return nil, err
+	}
+	return m.GetPayoutInfoFunc(ctx, payoutInfoReq)
+}
+
+func (m *Client) GetPayoutHistory(dateFrom time.Time, dateTo time.Time, cursor string) (*heleket.PayoutHistoryResponse, error) {
+	return m.GetPayoutHistoryContext(context.Background(), dateFrom, dateTo, cursor)
+}
+
+func (m *Client) GetPayoutHistoryContext(ctx context.Context, dateFrom time.Time, dateTo time.Time, cursor string) (*heleket.PayoutHistoryResponse, error) {
+	m.record(ctx, "GetPayoutHistory", dateFrom, dateTo, cursor)
+	if m.GetPayoutHistoryFunc == nil {
+		err := notStubbed("GetPayoutHistory")
+		return nil, err
+	}
+	return m.GetPayoutHistoryFunc(ctx, dateFrom, dateTo, cursor)
+}
+
+func (m *Client) GetPayoutServicesList() ([]*heleket.PayoutService, error) {
+	return m.GetPayoutServicesListContext(context.Background())
+}
+
+func (m *Client) GetPayoutServicesListContext(ctx context.Context) ([]*heleket.PayoutService, error) {
+	m.record(ctx, "GetPayoutServicesList")
+	if m.GetPayoutServicesListFunc == nil {
+		err := notStubbed("GetPayoutServicesList")
+		return nil, err
+	}
+	return m.GetPayoutServicesListFunc(ctx)
+}
+
+func (m *Client) CreateStaticWallet(staticWalletReq *heleket.StaticWalletRequest) (*heleket.StaticWalletResponse, error) {
+	return m.CreateStaticWalletContext(context.Background(), staticWalletReq)
+}
+
+func (m *Client) CreateStaticWalletContext(ctx context.Context, staticWalletReq *heleket.StaticWalletRequest) (*heleket.StaticWalletResponse, error) {
+	m.record(ctx, "CreateStaticWallet", staticWalletReq)
+	if m.CreateStaticWalletFunc == nil {
+		err := notStubbed("CreateStaticWallet")
+		return nil, err
+	}
+	return m.CreateStaticWalletFunc(ctx, staticWalletReq)
+}
+
+func (m *Client) GenerateStaticWalletQRCode(walletUUID string) (string, error) {
+	return m.GenerateStaticWalletQRCodeContext(context.Background(), walletUUID)
+}
+
+func (m *Client) GenerateStaticWalletQRCodeContext(ctx context.Context, walletUUID string) (string, error) {
+	m.record(ctx, "GenerateStaticWalletQRCode", walletUUID)
+	if m.GenerateStaticWalletQRCodeFunc == nil {
+		err := notStubbed("GenerateStaticWalletQRCode")
+		return "", err
+	}
+	return m.GenerateStaticWalletQRCodeFunc(ctx, walletUUID)
+}
+
+func (m *Client) BlockAddress(blockAddressReq *heleket.BlockAddressRequest) (*heleket.BlockAddressResponse, error) {
+	return m.BlockAddressContext(context.Background(), blockAddressReq)
+}
+
+func (m *Client) BlockAddressContext(ctx context.Context, blockAddressReq *heleket.BlockAddressRequest) (*heleket.BlockAddressResponse, error) {
+	m.record(ctx, "BlockAddress", blockAddressReq)
+	if m.BlockAddressFunc == nil {
+		err := notStubbed("BlockAddress")
+		return nil, err
+	}
+	return m.BlockAddressFunc(ctx, blockAddressReq)
+}
+
+func (m *Client) BlockedAddressRefund(refundRequest *heleket.BlockedAddressRefundRequest) (*heleket.BlockedAddressRefundResponse, error) {
+	return m.BlockedAddressRefundContext(context.Background(), refundRequest)
+}
+
+func (m *Client) BlockedAddressRefundContext(ctx context.Context, refundRequest *heleket.BlockedAddressRefundRequest) (*heleket.BlockedAddressRefundResponse, error) {
+	m.record(ctx, "BlockedAddressRefund", refundRequest)
+	if m.BlockedAddressRefundFunc == nil {
+		err := notStubbed("BlockedAddressRefund")
+		return nil, err
+	}
+	return m.BlockedAddressRefundFunc(ctx, refundRequest)
+}
+
+func (m *Client) ParseWebhook(reqBody []byte, verifySign bool) (*heleket.Webhook, error) {
+	return m.ParseWebhookContext(context.Background(), reqBody, verifySign)
+}
+
+func (m *Client) ParseWebhookContext(ctx context.Context, reqBody []byte, verifySign bool) (*heleket.Webhook, error) {
+	m.record(ctx, "ParseWebhook", reqBody, verifySign)
+	if m.ParseWebhookFunc == nil {
+		err := notStubbed("ParseWebhook")
+		return nil, err
+	}
+	return m.ParseWebhookFunc(ctx, reqBody, verifySign)
+}
+
+func (m *Client) VerifySign(apiKey string, reqBody []byte) error {
+	return m.VerifySignContext(context.Background(), apiKey, reqBody)
+}
+
+func (m *Client) VerifySignContext(ctx context.Context, apiKey string, reqBody []byte) error {
+	m.record(ctx, "VerifySign", apiKey, reqBody)
+	if m.VerifySignFunc == nil {
+		err := notStubbed("VerifySign")
+		return err
+	}
+	return m.VerifySignFunc(ctx, apiKey, reqBody)
+}
+
+func (m *Client) ResendWebhook(resendRequest *heleket.ResendWebhookRequest) (bool, error) {
+	return m.ResendWebhookContext(context.Background(), resendRequest)
+}
+
+func (m *Client) ResendWebhookContext(ctx context.Context, resendRequest *heleket.ResendWebhookRequest) (bool, error) {
+	m.record(ctx, "ResendWebhook", resendRequest)
+	if m.ResendWebhookFunc == nil {
+		err := notStubbed("ResendWebhook")
+		return false, err
+	}
+	return m.ResendWebhookFunc(ctx, resendRequest)
+}
+
+func (m *Client) TestPaymentWebhook(testRequest *heleket.TestWebhookRequest) (*heleket.TestWebhookResponse, error) {
+	return m.TestPaymentWebhookContext(context.Background(), testRequest)
+}
+
+func (m *Client) TestPaymentWebhookContext(ctx context.Context, testRequest *heleket.TestWebhookRequest) (*heleket.TestWebhookResponse, error) {
+	m.record(ctx, "TestPaymentWebhook", testRequest)
+	if m.TestPaymentWebhookFunc == nil {
+		err := notStubbed("TestPaymentWebhook")
+		return nil, err
+	}
+	return m.TestPaymentWebhookFunc(ctx, testRequest)
+}
+
+func (m *Client) TestPayoutWebhook(testRequest *heleket.TestWebhookRequest) (*heleket.TestWebhookResponse, error) {
+	return m.TestPayoutWebhookContext(context.Background(), testRequest)
+}
+
+func (m *Client) TestPayoutWebhookContext(ctx context.Context, testRequest *heleket.TestWebhookRequest) (*heleket.TestWebhookResponse, error) {
+	m.record(ctx, "TestPayoutWebhook", testRequest)
+	if m.TestPayoutWebhookFunc == nil {
+		err := notStubbed("TestPayoutWebhook")
+		return nil, err
+	}
+	return m.TestPayoutWebhookFunc(ctx, testRequest)
+}
+
+func (m *Client) TestWalletWebhook(testRequest *heleket.TestWebhookRequest) (*heleket.TestWebhookResponse, error) {
+	return m.TestWalletWebhookContext(context.Background(), testRequest)
+}
+
+func (m *Client) TestWalletWebhookContext(ctx context.Context, testRequest *heleket.TestWebhookRequest) (*heleket.TestWebhookResponse, error) {
+	m.record(ctx, "TestWalletWebhook", testRequest)
+	if m.TestWalletWebhookFunc == nil {
+		err := notStubbed("TestWalletWebhook")
+		return nil, err
+	}
+	return m.TestWalletWebhookFunc(ctx, testRequest)
+}
+
+func (m *Client) GetBalance() (*heleket.BalanceInfo, error) {
+	return m.GetBalanceContext(context.Background())
+}
+
+func (m *Client) GetBalanceContext(ctx context.Context) (*heleket.BalanceInfo, error) {
+	m.record(ctx, "GetBalance")
+	if m.GetBalanceFunc == nil {
+		err := notStubbed("GetBalance")
+		return nil, err
+	}
+	return m.GetBalanceFunc(ctx)
+}
+
+func (m *Client) GetDiscountsList() ([]*heleket.Discount, error) {
+	return m.GetDiscountsListContext(context.Background())
+}
+
+func (m *Client) GetDiscountsListContext(ctx context.Context) ([]*heleket.Discount, error) {
+	m.record(ctx, "GetDiscountsList")
+	if m.GetDiscountsListFunc == nil {
+		err := notStubbed("GetDiscountsList")
+		return nil, err
+	}
+	return m.GetDiscountsListFunc(ctx)
+}
+
+func (m *Client) SetDiscount(req *heleket.SetDiscountRequest) (*heleket.Discount, error) {
+	return m.SetDiscountContext(context.Background(), req)
+}
+
+func (m *Client) SetDiscountContext(ctx context.Context, req *heleket.SetDiscountRequest) (*heleket.Discount, error) {
+	m.record(ctx, "SetDiscount", req)
+	if m.SetDiscountFunc == nil {
+		err := notStubbed("SetDiscount")
+		return nil, err
+	}
+	return m.SetDiscountFunc(ctx, req)
+}
+
+func (m *Client) GetExchangeRates(currency string) ([]*heleket.ExchangeRate, error) {
+	return m.GetExchangeRatesContext(context.Background(), currency)
+}
+
+func (m *Client) GetExchangeRatesContext(ctx context.Context, currency string) ([]*heleket.ExchangeRate, error) {
+	m.record(ctx, "GetExchangeRates", currency)
+	if m.GetExchangeRatesFunc == nil {
+		err := notStubbed("GetExchangeRates")
+		return nil, err
+	}
+	return m.GetExchangeRatesFunc(ctx, currency)
+}
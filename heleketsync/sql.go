@@ -0,0 +1,272 @@
+package heleketsync
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/idanyas/heleket-go"
+)
+
+// sqlTimeFormat is fixed-width in UTC so that stored times sort as text.
+const sqlTimeFormat = "2006-01-02T15:04:05.000000000Z"
+
+// SQLStore is a Store backed by database/sql. Records are kept as JSON
+// next to the columns needed to look them up and order them, using only
+// portable SQL, so it works with any driver. Call Migrate once to create
+// the tables.
+type SQLStore struct {
+	db     *sql.DB
+	prefix string
+	dollar bool
+}
+
+// SQLOption configures an SQLStore created by NewSQLStore.
+type SQLOption func(*SQLStore)
+
+// WithTablePrefix sets the prefix of the store's table names. Defaults to
+// "heleket_".
+func WithTablePrefix(prefix string) SQLOption {
+	return func(s *SQLStore) {
+		s.prefix = prefix
+	}
+}
+
+// WithDollarPlaceholders writes query parameters as $1, $2, ... instead of
+// ?, for drivers such as PostgreSQL's.
+func WithDollarPlaceholders() SQLOption {
+	return func(s *SQLStore) {
+		s.dollar = true
+	}
+}
+
+// NewSQLStore returns a Store that uses db.
+func NewSQLStore(db *sql.DB, opts ...SQLOption) *SQLStore {
+	s := &SQLStore{db: db, prefix: "heleket_"}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Migrate creates the store's tables if they do not exist.
+func (s *SQLStore) Migrate(ctx context.Context) error {
+	statements := []string{
+		`CREATE TABLE IF NOT EXISTS {payments} (
+			uuid VARCHAR(64) PRIMARY KEY,
+			status VARCHAR(32) NOT NULL,
+			created_at VARCHAR(32) NOT NULL,
+			data TEXT NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS {payouts} (
+			uuid VARCHAR(64) PRIMARY KEY,
+			status VARCHAR(32) NOT NULL,
+			created_at VARCHAR(32) NOT NULL,
+			data TEXT NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS {marks} (
+			kind VARCHAR(32) PRIMARY KEY,
+			mark VARCHAR(32) NOT NULL
+		)`,
+	}
+	for _, statement := range statements {
+		if _, err := s.db.ExecContext(ctx, s.query(statement)); err != nil {
+			return fmt.Errorf("heleketsync: migrate: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *SQLStore) GetPayment(ctx context.Context, uuid string) (*heleket.Payment, error) {
+	payment := &heleket.Payment{}
+	if err := s.get(ctx, "{payments}", uuid, payment); err != nil {
+		return nil, err
+	}
+	return payment, nil
+}
+
+func (s *SQLStore) PutPayment(ctx context.Context, payment *heleket.Payment) error {
+	status := payment.PaymentStatus
+	if status == "" {
+		status = payment.Status
+	}
+	return s.put(ctx, "{payments}", payment.UUID, status, payment.CreatedAt, payment)
+}
+
+func (s *SQLStore) Payments(ctx context.Context) iter.Seq2[*heleket.Payment, error] {
+	return scanAll(ctx, s, "{payments}", func() *heleket.Payment { return &heleket.Payment{} })
+}
+
+func (s *SQLStore) GetPayout(ctx context.Context, uuid string) (*heleket.Payout, error) {
+	payout := &heleket.Payout{}
+	if err := s.get(ctx, "{payouts}", uuid, payout); err != nil {
+		return nil, err
+	}
+	return payout, nil
+}
+
+func (s *SQLStore) PutPayout(ctx context.Context, payout *heleket.Payout) error {
+	return s.put(ctx, "{payouts}", payout.UUID, payout.Status, payout.CreatedAt, payout)
+}
+
+func (s *SQLStore) Payouts(ctx context.Context) iter.Seq2[*heleket.Payout, error] {
+	return scanAll(ctx, s, "{payouts}", func() *heleket.Payout { return &heleket.Payout{} })
+}
+
+func (s *SQLStore) Mark(ctx context.Context, kind Kind) (time.Time, error) {
+	var mark string
+	err := s.db.QueryRowContext(ctx, s.query("SELECT mark FROM {marks} WHERE kind = ?"), string(kind)).Scan(&mark)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("heleketsync: read %s mark: %w", kind, err)
+	}
+	t, err := time.Parse(sqlTimeFormat, mark)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("heleketsync: read %s mark: %w", kind, err)
+	}
+	return t, nil
+}
+
+func (s *SQLStore) SetMark(ctx context.Context, kind Kind, mark time.Time) error {
+	value := mark.UTC().Format(sqlTimeFormat)
+	err := s.upsert(ctx,
+		"SELECT COUNT(*) FROM {marks} WHERE kind = ?",
+		"UPDATE {marks} SET mark = ? WHERE kind = ?",
+		"INSERT INTO {marks} (mark, kind) VALUES (?, ?)",
+		value, string(kind))
+	if err != nil {
+		return fmt.Errorf("heleketsync: write %s mark: %w", kind, err)
+	}
+	return nil
+}
+
+func (s *SQLStore) get(ctx context.Context, table, uuid string, v any) error {
+	var data string
+	err := s.db.QueryRowContext(ctx, s.query("SELECT data FROM "+table+" WHERE uuid = ?"), uuid).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("heleketsync: read %s: %w", uuid, err)
+	}
+	return json.Unmarshal([]byte(data), v)
+}
+
+func (s *SQLStore) put(ctx context.Context, table, uuid string, status heleket.Status, createdAt time.Time, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	err = s.upsert(ctx,
+		"SELECT COUNT(*) FROM "+table+" WHERE uuid = ?",
+		"UPDATE "+table+" SET status = ?, created_at = ?, data = ? WHERE uuid = ?",
+		"INSERT INTO "+table+" (status, created_at, data, uuid) VALUES (?, ?, ?, ?)",
+		string(status), createdAt.UTC().Format(sqlTimeFormat), string(data), uuid)
+	if err != nil {
+		return fmt.Errorf("heleketsync: write %s: %w", uuid, err)
+	}
+	return nil
+}
+
+// upsert runs update or insert in a transaction, depending on whether
+// count finds the row. The key is the last of args and the only argument
+// of count. ON CONFLICT and its relatives are not portable, and a zero
+// RowsAffected from UPDATE does not mean the row is missing on MySQL.
+//
+// Under READ COMMITTED another writer can insert the row between count and
+// insert, so a failed insert is retried once in a new transaction, which
+// then finds the row and updates it.
+func (s *SQLStore) upsert(ctx context.Context, count, update, insert string, args ...any) error {
+	inserted, err := s.upsertTx(ctx, count, update, insert, args)
+	if err == nil || !inserted || ctx.Err() != nil {
+		return err
+	}
+	if _, retryErr := s.upsertTx(ctx, count, update, insert, args); retryErr != nil {
+		return err
+	}
+	return nil
+}
+
+// upsertTx runs one upsert attempt and reports whether it tried to insert.
+func (s *SQLStore) upsertTx(ctx context.Context, count, update, insert string, args []any) (inserted bool, err error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var n int
+	if err := tx.QueryRowContext(ctx, s.query(count), args[len(args)-1]).Scan(&n); err != nil {
+		return false, err
+	}
+	statement := insert
+	if n > 0 {
+		statement = update
+	}
+	if _, err := tx.ExecContext(ctx, s.query(statement), args...); err != nil {
+		return n == 0, err
+	}
+	return false, tx.Commit()
+}
+
+func scanAll[T any](ctx context.Context, s *SQLStore, table string, newT func() T) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		rows, err := s.db.QueryContext(ctx, s.query("SELECT data FROM "+table+" ORDER BY created_at, uuid"))
+		if err != nil {
+			yield(zero, fmt.Errorf("heleketsync: list %s: %w", strings.Trim(table, "{}"), err))
+			return
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var data string
+			if err := rows.Scan(&data); err != nil {
+				yield(zero, err)
+				return
+			}
+			v := newT()
+			if err := json.Unmarshal([]byte(data), v); err != nil {
+				yield(zero, err)
+				return
+			}
+			if !yield(v, nil) {
+				return
+			}
+		}
+		if err := rows.Err(); err != nil {
+			yield(zero, err)
+		}
+	}
+}
+
+// query expands table names and placeholders.
+func (s *SQLStore) query(q string) string {
+	q = strings.NewReplacer(
+		"{payments}", s.prefix+"payments",
+		"{payouts}", s.prefix+"payouts",
+		"{marks}", s.prefix+"sync_marks",
+	).Replace(q)
+	if !s.dollar {
+		return q
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range q {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,176 @@
+package heleketsync
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/idanyas/heleket-go"
+)
+
+// ErrNotFound is returned by a Store for a record it does not hold.
+var ErrNotFound = errors.New("heleketsync: not found")
+
+// Kind names a type of synced record.
+type Kind string
+
+const (
+	KindPayment Kind = "payment"
+	KindPayout  Kind = "payout"
+)
+
+// Store persists synced records and the high-water mark of each kind.
+// Implementations must be safe for concurrent use.
+type Store interface {
+	// GetPayment returns the stored payment, or ErrNotFound.
+	GetPayment(ctx context.Context, uuid string) (*heleket.Payment, error)
+	// PutPayment inserts or replaces a payment by UUID.
+	PutPayment(ctx context.Context, payment *heleket.Payment) error
+	// Payments iterates over the stored payments, oldest first.
+	Payments(ctx context.Context) iter.Seq2[*heleket.Payment, error]
+
+	// GetPayout returns the stored payout, or ErrNotFound.
+	GetPayout(ctx context.Context, uuid string) (*heleket.Payout, error)
+	// PutPayout inserts or replaces a payout by UUID.
+	PutPayout(ctx context.Context, payout *heleket.Payout) error
+	// Payouts iterates over the stored payouts, oldest first.
+	Payouts(ctx context.Context) iter.Seq2[*heleket.Payout, error]
+
+	// Mark returns the high-water mark of kind, or the zero time if it was
+	// never set.
+	Mark(ctx context.Context, kind Kind) (time.Time, error)
+	// SetMark persists the high-water mark of kind.
+	SetMark(ctx context.Context, kind Kind, mark time.Time) error
+}
+
+// MemoryStore is a Store that keeps records in memory. The zero value is
+// ready to use.
+type MemoryStore struct {
+	mu       sync.RWMutex
+	payments map[string]*heleket.Payment
+	payouts  map[string]*heleket.Payout
+	marks    map[Kind]time.Time
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+func (s *MemoryStore) GetPayment(ctx context.Context, uuid string) (*heleket.Payment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	p, ok := s.payments[uuid]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return copyPayment(p), nil
+}
+
+func (s *MemoryStore) PutPayment(ctx context.Context, payment *heleket.Payment) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.payments == nil {
+		s.payments = map[string]*heleket.Payment{}
+	}
+	s.payments[payment.UUID] = copyPayment(payment)
+	return nil
+}
+
+func (s *MemoryStore) Payments(ctx context.Context) iter.Seq2[*heleket.Payment, error] {
+	return func(yield func(*heleket.Payment, error) bool) {
+		s.mu.RLock()
+		payments := make([]*heleket.Payment, 0, len(s.payments))
+		for _, p := range s.payments {
+			payments = append(payments, copyPayment(p))
+		}
+		s.mu.RUnlock()
+
+		slices.SortFunc(payments, func(a, b *heleket.Payment) int {
+			if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+				return c
+			}
+			return strings.Compare(a.UUID, b.UUID)
+		})
+		for _, p := range payments {
+			if !yield(p, nil) {
+				return
+			}
+		}
+	}
+}
+
+func (s *MemoryStore) GetPayout(ctx context.Context, uuid string) (*heleket.Payout, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	p, ok := s.payouts[uuid]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	copied := *p
+	return &copied, nil
+}
+
+func (s *MemoryStore) PutPayout(ctx context.Context, payout *heleket.Payout) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.payouts == nil {
+		s.payouts = map[string]*heleket.Payout{}
+	}
+	copied := *payout
+	s.payouts[payout.UUID] = &copied
+	return nil
+}
+
+func (s *MemoryStore) Payouts(ctx context.Context) iter.Seq2[*heleket.Payout, error] {
+	return func(yield func(*heleket.Payout, error) bool) {
+		s.mu.RLock()
+		payouts := make([]*heleket.Payout, 0, len(s.payouts))
+		for _, p := range s.payouts {
+			copied := *p
+			payouts = append(payouts, &copied)
+		}
+		s.mu.RUnlock()
+
+		slices.SortFunc(payouts, func(a, b *heleket.Payout) int {
+			if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+				return c
+			}
+			return strings.Compare(a.UUID, b.UUID)
+		})
+		for _, p := range payouts {
+			if !yield(p, nil) {
+				return
+			}
+		}
+	}
+}
+
+func (s *MemoryStore) Mark(ctx context.Context, kind Kind) (time.Time, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.marks[kind], nil
+}
+
+func (s *MemoryStore) SetMark(ctx context.Context, kind Kind, mark time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.marks == nil {
+		s.marks = map[Kind]time.Time{}
+	}
+	s.marks[kind] = mark
+	return nil
+}
+
+func copyPayment(p *heleket.Payment) *heleket.Payment {
+	copied := *p
+	if p.Convert != nil {
+		convert := *p.Convert
+		copied.Convert = &convert
+	}
+	return &copied
+}
@@ -0,0 +1,382 @@
+// Package heleketsync keeps a local mirror of Heleket payments and payouts.
+//
+// A Syncer polls the history endpoints from a persisted high-water mark,
+// upserts new and changed records into a Store by UUID and notifies
+// subscribers of new records and status transitions:
+//
+//	syncer := heleketsync.New(client, heleketsync.NewMemoryStore())
+//	syncer.Subscribe(func(e heleketsync.Event) {
+//		log.Printf("%s %s: %s -> %s", e.Kind, e.UUID, e.From, e.To)
+//	})
+//	err := syncer.Run(ctx)
+//
+// History is filtered by creation date, so each sync reads the records
+// created within the lookback and polls older stored records one by one
+// until they are final. A record that changes after it became final and
+// left the lookback, such as a paid invoice refunded days later, is not
+// seen again: raise WithLookback or fetch it with GetPaymentInfo if that
+// matters.
+//
+// Use NewSQLStore to keep the mirror in a database instead.
+package heleketsync
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/idanyas/heleket-go"
+)
+
+// Defaults used by New.
+const (
+	DefaultInterval = time.Minute
+	// DefaultLookback covers the longest invoice lifetime with room to
+	// spare, so most payments settle while still in the history range.
+	DefaultLookback = 24 * time.Hour
+)
+
+// API is the part of heleket.Client a Syncer uses.
+type API interface {
+	heleket.PaymentsAPI
+	heleket.PayoutsAPI
+}
+
+// Event reports a new record or a status transition. From is empty for
+// records seen for the first time. Exactly one of Payment and Payout is
+// set, according to Kind.
+type Event struct {
+	Kind    Kind
+	UUID    string
+	From    heleket.Status
+	To      heleket.Status
+	Payment *heleket.Payment
+	Payout  *heleket.Payout
+}
+
+// Created reports whether the event is for a record seen for the first time.
+func (e Event) Created() bool {
+	return e.From == ""
+}
+
+// Option configures a Syncer created by New.
+type Option func(*Syncer)
+
+// WithInterval sets how often Run syncs. Defaults to DefaultInterval,
+// which is also used if interval <= 0.
+func WithInterval(interval time.Duration) Option {
+	return func(s *Syncer) {
+		if interval <= 0 {
+			interval = DefaultInterval
+		}
+		s.interval = interval
+	}
+}
+
+// WithLookback sets how far before the high-water mark each sync reads
+// history. Older records are polled individually while they are not final.
+// Defaults to DefaultLookback, which is also used if lookback <= 0.
+func WithLookback(lookback time.Duration) Option {
+	return func(s *Syncer) {
+		if lookback <= 0 {
+			lookback = DefaultLookback
+		}
+		s.lookback = lookback
+	}
+}
+
+// WithStart sets where the first sync starts when the store has no
+// high-water mark yet. Defaults to the lookback before now.
+func WithStart(start time.Time) Option {
+	return func(s *Syncer) {
+		s.start = start
+	}
+}
+
+// WithWindow sets the longest date range requested at once. See
+// heleket.HistoryQuery.Window.
+func WithWindow(window time.Duration) Option {
+	return func(s *Syncer) {
+		s.window = window
+	}
+}
+
+// WithErrorHandler sets a function called with the error of each failed
+// sync in Run. Run keeps polling either way.
+func WithErrorHandler(fn func(error)) Option {
+	return func(s *Syncer) {
+		s.onError = fn
+	}
+}
+
+// WithClock sets the clock used for the end of each sync range.
+func WithClock(now func() time.Time) Option {
+	return func(s *Syncer) {
+		s.now = now
+	}
+}
+
+// Syncer mirrors payments and payouts into a Store.
+type Syncer struct {
+	api      API
+	store    Store
+	interval time.Duration
+	lookback time.Duration
+	start    time.Time
+	window   time.Duration
+	onError  func(error)
+	now      func() time.Time
+
+	syncMu sync.Mutex
+
+	subMu       sync.Mutex
+	subscribers []subscriber
+	nextID      int
+}
+
+type subscriber struct {
+	id int
+	fn func(Event)
+}
+
+// New returns a Syncer that reads from api and writes to store.
+func New(api API, store Store, opts ...Option) *Syncer {
+	s := &Syncer{
+		api:      api,
+		store:    store,
+		interval: DefaultInterval,
+		lookback: DefaultLookback,
+		window:   heleket.DefaultHistoryWindow,
+		now:      time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Subscribe registers fn to be called for every event, in the order the
+// changes are stored, from the goroutine running the sync. Subscribers are
+// called in the order they subscribed. It returns a function that removes
+// the subscription.
+func (s *Syncer) Subscribe(fn func(Event)) (unsubscribe func()) {
+	s.subMu.Lock()
+	defer s.subMu.Unlock()
+	id := s.nextID
+	s.nextID++
+	s.subscribers = append(s.subscribers, subscriber{id: id, fn: fn})
+	return func() {
+		s.subMu.Lock()
+		defer s.subMu.Unlock()
+		s.subscribers = slices.DeleteFunc(slices.Clone(s.subscribers), func(sub subscriber) bool { return sub.id == id })
+	}
+}
+
+// Run syncs immediately and then every interval until ctx is done, and
+// returns ctx.Err().
+func (s *Syncer) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		if err := s.Sync(ctx); err != nil && s.onError != nil && ctx.Err() == nil {
+			s.onError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sync runs one pass over payments and then payouts. A pass reads the
+// history created since the lookback before the high-water mark, then
+// polls each stored record created before that range that is not final,
+// so late status changes still produce events. The high-water mark of a
+// kind only advances if its pass completes; records stored before a
+// failure are kept.
+func (s *Syncer) Sync(ctx context.Context) error {
+	s.syncMu.Lock()
+	defer s.syncMu.Unlock()
+
+	return errors.Join(
+		s.syncKind(ctx, KindPayment, s.syncPayments),
+		s.syncKind(ctx, KindPayout, s.syncPayouts),
+	)
+}
+
+func (s *Syncer) syncKind(ctx context.Context, kind Kind, pass func(ctx context.Context, from, to time.Time) error) error {
+	mark, err := s.store.Mark(ctx, kind)
+	if err != nil {
+		return err
+	}
+	// History dates have whole seconds, so the range ends at the next
+	// whole second to cover everything created up to now.
+	now := s.now()
+	to := now.Truncate(time.Second)
+	if to.Before(now) {
+		to = to.Add(time.Second)
+	}
+	to = to.UTC()
+	from := mark.Add(-s.lookback)
+	if mark.IsZero() {
+		from = s.start
+		if from.IsZero() {
+			from = to.Add(-s.lookback)
+		}
+	}
+
+	if err := pass(ctx, from, to); err != nil {
+		return err
+	}
+	return s.store.SetMark(ctx, kind, to)
+}
+
+func (s *Syncer) syncPayments(ctx context.Context, from, to time.Time) error {
+	for payment, err := range heleket.NewHistoryQuery(from, to).Window(s.window).Payments(ctx, s.api) {
+		if err != nil {
+			return err
+		}
+		if err := s.storePayment(ctx, payment); err != nil {
+			return err
+		}
+	}
+
+	var pending []string
+	for payment, err := range s.store.Payments(ctx) {
+		if err != nil {
+			return err
+		}
+		if !payment.IsFinal && payment.CreatedAt.Before(from) {
+			pending = append(pending, payment.UUID)
+		}
+	}
+	for _, uuid := range pending {
+		payment, err := s.api.GetPaymentInfoContext(ctx, &heleket.PaymentInfoRequest{PaymentUUID: uuid})
+		if errors.Is(err, heleket.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if payment == nil {
+			continue
+		}
+		if err := s.storePayment(ctx, payment); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Syncer) syncPayouts(ctx context.Context, from, to time.Time) error {
+	for payout, err := range heleket.NewHistoryQuery(from, to).Window(s.window).Payouts(ctx, s.api) {
+		if err != nil {
+			return err
+		}
+		if err := s.storePayout(ctx, payout); err != nil {
+			return err
+		}
+	}
+
+	var pending []string
+	for payout, err := range s.store.Payouts(ctx) {
+		if err != nil {
+			return err
+		}
+		if !payout.IsFinal && payout.CreatedAt.Before(from) {
+			pending = append(pending, payout.UUID)
+		}
+	}
+	for _, uuid := range pending {
+		payout, err := s.api.GetPayoutInfoContext(ctx, &heleket.PayoutInfoRequest{PayoutUUID: uuid})
+		if errors.Is(err, heleket.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if payout == nil {
+			continue
+		}
+		if err := s.storePayout(ctx, payout); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// storePayment stores payment if it changed and emits an event if it is
+// new or its status changed.
+func (s *Syncer) storePayment(ctx context.Context, payment *heleket.Payment) error {
+	stored, err := s.store.GetPayment(ctx, payment.UUID)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	if stored != nil && sameRecord(stored, payment) {
+		return nil
+	}
+	if err := s.store.PutPayment(ctx, payment); err != nil {
+		return err
+	}
+
+	event := Event{Kind: KindPayment, UUID: payment.UUID, To: paymentStatus(payment), Payment: payment}
+	if stored != nil {
+		event.From = paymentStatus(stored)
+	}
+	if stored == nil || event.From != event.To {
+		s.emit(event)
+	}
+	return nil
+}
+
+// storePayout is storePayment for payouts.
+func (s *Syncer) storePayout(ctx context.Context, payout *heleket.Payout) error {
+	stored, err := s.store.GetPayout(ctx, payout.UUID)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	if stored != nil && sameRecord(stored, payout) {
+		return nil
+	}
+	if err := s.store.PutPayout(ctx, payout); err != nil {
+		return err
+	}
+
+	event := Event{Kind: KindPayout, UUID: payout.UUID, To: payout.Status, Payout: payout}
+	if stored != nil {
+		event.From = stored.Status
+	}
+	if stored == nil || event.From != event.To {
+		s.emit(event)
+	}
+	return nil
+}
+
+func (s *Syncer) emit(event Event) {
+	s.subMu.Lock()
+	subscribers := s.subscribers
+	s.subMu.Unlock()
+	for _, sub := range subscribers {
+		sub.fn(event)
+	}
+}
+
+func paymentStatus(p *heleket.Payment) heleket.Status {
+	if p.PaymentStatus != "" {
+		return p.PaymentStatus
+	}
+	return p.Status
+}
+
+// sameRecord compares records by their JSON form, which is what a Store
+// such as SQLStore round-trips.
+func sameRecord(a, b any) bool {
+	x, errX := json.Marshal(a)
+	y, errY := json.Marshal(b)
+	return errX == nil && errY == nil && bytes.Equal(x, y)
+}
@@ -0,0 +1,244 @@
+package heleket
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"slices"
+	"strings"
+	"time"
+)
+
+// historyTimeFormat is the date format of the history endpoints.
+const historyTimeFormat = "2006-01-02 15:04:05"
+
+// DefaultHistoryWindow is the longest date range a HistoryQuery requests
+// from Heleket in one go unless Window is set.
+const DefaultHistoryWindow = 30 * 24 * time.Hour
+
+// WithAPILocation sets the time zone Heleket interprets history dates in.
+// GetPaymentHistory and GetPayoutHistory convert their bounds to it before
+// formatting, so callers can pass times in any location. Defaults to UTC.
+func WithAPILocation(loc *time.Location) Option {
+	return func(c *Heleket) {
+		c.apiLocation = loc
+	}
+}
+
+func (c *Heleket) historyPayload(dateFrom, dateTo time.Time) map[string]any {
+	return map[string]any{
+		"date_from": dateFrom.In(c.apiLocation).Format(historyTimeFormat),
+		"date_to":   dateTo.In(c.apiLocation).Format(historyTimeFormat),
+	}
+}
+
+// errPayoutOrderId is yielded for a payout query with an order id prefix.
+var errPayoutOrderId = errors.New("heleket: payout history has no order_id to match OrderIdPrefix against")
+
+// HistoryWindow is one date range a HistoryQuery requests.
+type HistoryWindow struct {
+	From time.Time
+	To   time.Time
+}
+
+// HistoryQuery iterates over payment or payout history across a date range
+// of any length. The range is split into windows no longer than the
+// configured maximum, each fetched in turn, and items are filtered
+// client-side and de-duplicated by UUID across window boundaries.
+//
+// Dates are sent in the client's API location (see WithAPILocation), so
+// the range is exact regardless of the caller's local time zone.
+type HistoryQuery struct {
+	from, to      time.Time
+	window        time.Duration
+	prefetch      int
+	statuses      []Status
+	currencies    []string
+	networks      []string
+	orderIdPrefix string
+	minAmount     Amount
+	maxAmount     Amount
+}
+
+// NewHistoryQuery returns a query for history created between from and to.
+func NewHistoryQuery(from, to time.Time) *HistoryQuery {
+	return &HistoryQuery{from: from, to: to, window: DefaultHistoryWindow}
+}
+
+// Window sets the longest date range requested at once.
+func (q *HistoryQuery) Window(d time.Duration) *HistoryQuery {
+	if d > 0 {
+		q.window = d
+	}
+	return q
+}
+
+// Prefetch fetches up to pages history pages ahead of the consumer. See
+// WithPrefetch.
+func (q *HistoryQuery) Prefetch(pages int) *HistoryQuery {
+	q.prefetch = pages
+	return q
+}
+
+// Status keeps items with one of the given statuses.
+func (q *HistoryQuery) Status(statuses ...Status) *HistoryQuery {
+	q.statuses = append(q.statuses, statuses...)
+	return q
+}
+
+// Currency keeps items in one of the given currencies. A payment matches
+// on either its invoice currency or its payer currency.
+func (q *HistoryQuery) Currency(currencies ...string) *HistoryQuery {
+	for _, currency := range currencies {
+		q.currencies = append(q.currencies, strings.ToUpper(currency))
+	}
+	return q
+}
+
+// Network keeps items on one of the given networks.
+func (q *HistoryQuery) Network(networks ...string) *HistoryQuery {
+	for _, network := range networks {
+		q.networks = append(q.networks, strings.ToLower(network))
+	}
+	return q
+}
+
+// OrderIdPrefix keeps payments whose order_id starts with prefix. Payout
+// history carries no order_id, so Payouts and BackfillPayouts reject a
+// query with an order id prefix.
+func (q *HistoryQuery) OrderIdPrefix(prefix string) *HistoryQuery {
+	q.orderIdPrefix = prefix
+	return q
+}
+
+// AmountRange keeps items whose amount is within [min, max]. A null bound
+// is open.
+func (q *HistoryQuery) AmountRange(min, max Amount) *HistoryQuery {
+	q.minAmount, q.maxAmount = min, max
+	return q
+}
+
+// Windows returns the date ranges the query requests, in chronological
+// order. Adjacent windows share their boundary.
+func (q *HistoryQuery) Windows() []HistoryWindow {
+	if !q.from.Before(q.to) {
+		return []HistoryWindow{{From: q.from, To: q.to}}
+	}
+	var windows []HistoryWindow
+	for start := q.from; start.Before(q.to); {
+		end := start.Add(q.window)
+		if end.After(q.to) {
+			end = q.to
+		}
+		windows = append(windows, HistoryWindow{From: start, To: end})
+		start = end
+	}
+	return windows
+}
+
+// Payments iterates over the matching payments. Errors are yielded once
+// and end the sequence.
+func (q *HistoryQuery) Payments(ctx context.Context, api PaymentsAPI) iter.Seq2[*Payment, error] {
+	return historySeq(ctx, q.Windows(), q.prefetch,
+		func(w HistoryWindow) pageFunc[*Payment] { return paymentPages(api, w.From, w.To) },
+		func(p *Payment) string { return p.UUID },
+		q.matchPayment)
+}
+
+// Payouts iterates over the matching payouts. Errors are yielded once and
+// end the sequence.
+func (q *HistoryQuery) Payouts(ctx context.Context, api PayoutsAPI) iter.Seq2[*Payout, error] {
+	if q.orderIdPrefix != "" {
+		return errSeq[*Payout](errPayoutOrderId)
+	}
+	return historySeq(ctx, q.Windows(), q.prefetch,
+		func(w HistoryWindow) pageFunc[*Payout] { return payoutPages(api, w.From, w.To) },
+		func(p *Payout) string { return p.UUID },
+		q.matchPayout)
+}
+
+func (q *HistoryQuery) matchPayment(p *Payment) bool {
+	status := p.PaymentStatus
+	if status == "" {
+		status = p.Status
+	}
+	return q.matchStatus(status) &&
+		(len(q.currencies) == 0 ||
+			slices.Contains(q.currencies, strings.ToUpper(p.Currency)) ||
+			slices.Contains(q.currencies, strings.ToUpper(p.PayerCurrency))) &&
+		q.matchNetwork(p.Network) &&
+		strings.HasPrefix(p.OrderId, q.orderIdPrefix) &&
+		q.matchAmount(p.Amount)
+}
+
+func (q *HistoryQuery) matchPayout(p *Payout) bool {
+	return q.matchStatus(p.Status) &&
+		(len(q.currencies) == 0 || slices.Contains(q.currencies, strings.ToUpper(p.Currency))) &&
+		q.matchNetwork(p.Network) &&
+		q.matchAmount(p.Amount)
+}
+
+func (q *HistoryQuery) matchStatus(status Status) bool {
+	return len(q.statuses) == 0 || slices.Contains(q.statuses, status)
+}
+
+func (q *HistoryQuery) matchNetwork(network string) bool {
+	return len(q.networks) == 0 || slices.Contains(q.networks, strings.ToLower(network))
+}
+
+func (q *HistoryQuery) matchAmount(amount string) bool {
+	if q.minAmount.IsNull() && q.maxAmount.IsNull() {
+		return true
+	}
+	value := amountOf(amount)
+	if value.IsNull() {
+		return false
+	}
+	return (q.minAmount.IsNull() || !value.LessThan(q.minAmount)) &&
+		(q.maxAmount.IsNull() || !value.GreaterThan(q.maxAmount))
+}
+
+// errSeq yields err once.
+func errSeq[T any](err error) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		yield(zero, err)
+	}
+}
+
+// historySeq chains the pages of each window, skipping items already seen
+// in the window or the one before it and items that do not match.
+func historySeq[T any](ctx context.Context, windows []HistoryWindow, prefetch int,
+	pages func(HistoryWindow) pageFunc[T], key func(T) string, match func(T) bool) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		var previous map[string]struct{}
+		for _, w := range windows {
+			// Only the previous window can hold an item on the shared
+			// boundary, so older keys are dropped to bound memory.
+			seen := map[string]struct{}{}
+			for item, err := range pageSeq(ctx, pages(w), WithPrefetch(prefetch)) {
+				if err != nil {
+					yield(zero, err)
+					return
+				}
+				if k := key(item); k != "" {
+					if _, dup := previous[k]; dup {
+						continue
+					}
+					if _, dup := seen[k]; dup {
+						continue
+					}
+					seen[k] = struct{}{}
+				}
+				if !match(item) {
+					continue
+				}
+				if !yield(item, nil) {
+					return
+				}
+			}
+			previous = seen
+		}
+	}
+}
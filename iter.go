@@ -0,0 +1,169 @@
+package heleket
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"time"
+)
+
+// ErrCursorLoop is yielded when Heleket returns the cursor that was just
+// requested as the next one, which would otherwise repeat forever.
+var ErrCursorLoop = errors.New("heleket: history cursor did not advance")
+
+// SeqOption configures PaymentsSeq and PayoutsSeq.
+type SeqOption func(*seqConfig)
+
+type seqConfig struct {
+	prefetch int
+}
+
+// WithPrefetch fetches up to pages history pages ahead of the loop body in
+// a background goroutine. Without it each page is fetched when the
+// previous one has been consumed.
+func WithPrefetch(pages int) SeqOption {
+	return func(c *seqConfig) {
+		c.prefetch = pages
+	}
+}
+
+// PaymentsSeq iterates over the payment history between from and to,
+// following cursors until the last page. Errors are yielded once, with a
+// nil payment, and end the sequence. Breaking out of the loop stops
+// fetching.
+func (c *Heleket) PaymentsSeq(ctx context.Context, from, to time.Time, opts ...SeqOption) iter.Seq2[*Payment, error] {
+	return pageSeq(ctx, paymentPages(c, from, to), opts...)
+}
+
+// PayoutsSeq iterates over the payout history between from and to. It
+// behaves like PaymentsSeq.
+func (c *Heleket) PayoutsSeq(ctx context.Context, from, to time.Time, opts ...SeqOption) iter.Seq2[*Payout, error] {
+	return pageSeq(ctx, payoutPages(c, from, to), opts...)
+}
+
+// pageFunc fetches the history page at cursor and returns its items and
+// the next cursor, which is empty on the last page.
+type pageFunc[T any] func(ctx context.Context, cursor string) (items []T, next string, err error)
+
+func paymentPages(api PaymentsAPI, from, to time.Time) pageFunc[*Payment] {
+	return func(ctx context.Context, cursor string) ([]*Payment, string, error) {
+		res, err := api.GetPaymentHistoryContext(ctx, from, to, cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		var next string
+		if res.Paginate != nil {
+			next = res.Paginate.NextCursor
+		}
+		return res.Payments, next, nil
+	}
+}
+
+func payoutPages(api PayoutsAPI, from, to time.Time) pageFunc[*Payout] {
+	return func(ctx context.Context, cursor string) ([]*Payout, string, error) {
+		res, err := api.GetPayoutHistoryContext(ctx, from, to, cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		var next string
+		if res.Paginate != nil {
+			next = res.Paginate.NextCursor
+		}
+		return res.Payouts, next, nil
+	}
+}
+
+type page[T any] struct {
+	items []T
+	err   error
+}
+
+func pageSeq[T any](ctx context.Context, fetch pageFunc[T], opts ...SeqOption) iter.Seq2[T, error] {
+	var cfg seqConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.prefetch > 0 {
+		return prefetchSeq(ctx, fetch, cfg.prefetch)
+	}
+
+	return func(yield func(T, error) bool) {
+		var zero T
+		cursor := ""
+		for {
+			items, next, err := fetch(ctx, cursor)
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+			if next == "" {
+				return
+			}
+			if next == cursor {
+				yield(zero, ErrCursorLoop)
+				return
+			}
+			cursor = next
+		}
+	}
+}
+
+// prefetchSeq fetches pages in a goroutine, buffering up to prefetch pages
+// ahead of the consumer. The goroutine is stopped and waited for when the
+// loop ends, however it ends.
+func prefetchSeq[T any](ctx context.Context, fetch pageFunc[T], prefetch int) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		pages := make(chan page[T], prefetch)
+		stop := make(chan struct{})
+		done := make(chan struct{})
+		defer func() {
+			close(stop)
+			cancel()
+			<-done
+		}()
+
+		go func() {
+			defer close(done)
+			defer close(pages)
+			send := func(p page[T]) bool {
+				select {
+				case pages <- p:
+					return true
+				case <-stop:
+					return false
+				}
+			}
+			cursor := ""
+			for {
+				items, next, err := fetch(ctx, cursor)
+				if !send(page[T]{items: items, err: err}) || err != nil || next == "" {
+					return
+				}
+				if next == cursor {
+					send(page[T]{err: ErrCursorLoop})
+					return
+				}
+				cursor = next
+			}
+		}()
+
+		var zero T
+		for p := range pages {
+			if p.err != nil {
+				yield(zero, p.err)
+				return
+			}
+			for _, item := range p.items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+		}
+	}
+}
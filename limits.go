@@ -0,0 +1,227 @@
+package heleket
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrServiceUnavailable is matched by a LimitError for a currency and
+	// network pair that is not listed or not available.
+	ErrServiceUnavailable = errors.New("heleket: service unavailable")
+	// ErrAmountOutOfRange is matched by a LimitError for an amount outside
+	// the service's MinAmount and MaxAmount.
+	ErrAmountOutOfRange = errors.New("heleket: amount out of range")
+)
+
+// LimitError is returned by CreateInvoice and CreatePayout when limit checks
+// are enabled and the request falls outside what the services list allows.
+type LimitError struct {
+	KeyKind  KeyKind
+	Currency string
+	Network  string
+	// Unavailable is set if the pair is not listed or not available.
+	Unavailable bool
+	// Amount is the requested amount; Min and Max are the allowed range.
+	// Min or Max is null if Heleket does not report it.
+	Amount Amount
+	Min    Amount
+	Max    Amount
+}
+
+func (e *LimitError) Error() string {
+	if e.Unavailable {
+		return fmt.Sprintf("heleket: %s %s on %s is not available", e.KeyKind, e.Currency, e.Network)
+	}
+	return fmt.Sprintf("heleket: %s amount %s %s on %s is outside the allowed range %s to %s",
+		e.KeyKind, e.Amount, e.Currency, e.Network, boundString(e.Min), boundString(e.Max))
+}
+
+func (e *LimitError) Is(target error) bool {
+	switch target {
+	case ErrServiceUnavailable:
+		return e.Unavailable
+	case ErrAmountOutOfRange:
+		return !e.Unavailable
+	}
+	return false
+}
+
+func boundString(a Amount) string {
+	if a.IsNull() {
+		return "unbounded"
+	}
+	return a.String()
+}
+
+// WithLimitChecks makes CreateInvoice and CreatePayout check the currency,
+// network and amount against the payment and payout services lists before
+// sending the request. The lists are fetched on first use and cached for
+// ttl (10 minutes if ttl <= 0). If a list cannot be fetched the check is
+// skipped and Heleket enforces its limits as usual.
+func WithLimitChecks(ttl time.Duration) Option {
+	return func(c *Heleket) {
+		if ttl <= 0 {
+			ttl = 10 * time.Minute
+		}
+		c.services = &servicesCache{ttl: ttl}
+	}
+}
+
+type serviceLimit struct {
+	available bool
+	min       Amount
+	max       Amount
+}
+
+type servicesCache struct {
+	ttl time.Duration
+
+	mu       sync.Mutex
+	entries  map[KeyKind]*servicesEntry
+	inflight map[KeyKind]*servicesFetch
+}
+
+type servicesEntry struct {
+	fetchedAt time.Time
+	limits    map[servicePair]serviceLimit
+}
+
+// servicesFetch is a services list fetch shared by every caller that finds
+// the cache missing or stale while it runs. done is closed once limits and
+// err are set.
+type servicesFetch struct {
+	done   chan struct{}
+	limits map[servicePair]serviceLimit
+	err    error
+}
+
+// limits returns the cached services for keyKind, fetching them if they
+// are missing or stale. Concurrent callers share one fetch, which is not
+// canceled with the caller that started it; a caller whose ctx is done
+// stops waiting. A stale list is kept if refetching fails.
+func (s *servicesCache) limits(ctx context.Context, c *Heleket, keyKind KeyKind) map[servicePair]serviceLimit {
+	s.mu.Lock()
+	entry := s.entries[keyKind]
+	if entry != nil && time.Since(entry.fetchedAt) < s.ttl {
+		s.mu.Unlock()
+		return entry.limits
+	}
+	fetch := s.inflight[keyKind]
+	if fetch == nil {
+		fetch = &servicesFetch{done: make(chan struct{})}
+		if s.inflight == nil {
+			s.inflight = map[KeyKind]*servicesFetch{}
+		}
+		s.inflight[keyKind] = fetch
+		go s.fetch(context.WithoutCancel(ctx), c, keyKind, fetch)
+	}
+	s.mu.Unlock()
+
+	select {
+	case <-fetch.done:
+		if fetch.err == nil {
+			return fetch.limits
+		}
+	case <-ctx.Done():
+	}
+	if entry != nil {
+		return entry.limits
+	}
+	return nil
+}
+
+// fetch runs a shared fetch and publishes its result.
+func (s *servicesCache) fetch(ctx context.Context, c *Heleket, keyKind KeyKind, fetch *servicesFetch) {
+	limits, err := c.fetchServiceLimits(ctx, keyKind)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err == nil {
+		if s.entries == nil {
+			s.entries = map[KeyKind]*servicesEntry{}
+		}
+		s.entries[keyKind] = &servicesEntry{fetchedAt: time.Now(), limits: limits}
+	}
+	delete(s.inflight, keyKind)
+	fetch.limits, fetch.err = limits, err
+	close(fetch.done)
+}
+
+func (c *Heleket) fetchServiceLimits(ctx context.Context, keyKind KeyKind) (map[servicePair]serviceLimit, error) {
+	limits := map[servicePair]serviceLimit{}
+	add := func(currency, network string, available bool, minAmount, maxAmount string) {
+		pair := servicePair{strings.ToUpper(currency), strings.ToLower(network)}
+		limits[pair] = serviceLimit{available: available, min: amountOf(minAmount), max: amountOf(maxAmount)}
+	}
+
+	switch keyKind {
+	case PaymentKey:
+		services, err := c.GetPaymentServicesListContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range services {
+			var minAmount, maxAmount string
+			if s.Limit != nil {
+				minAmount, maxAmount = s.Limit.MinAmount, s.Limit.MaxAmount
+			}
+			add(s.Currency, s.Network, s.IsAvailable, minAmount, maxAmount)
+		}
+	case PayoutKey:
+		services, err := c.GetPayoutServicesListContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range services {
+			var minAmount, maxAmount string
+			if s.Limit != nil {
+				minAmount, maxAmount = s.Limit.MinAmount, s.Limit.MaxAmount
+			}
+			add(s.Currency, s.Network, s.IsAvailable, minAmount, maxAmount)
+		}
+	}
+	return limits, nil
+}
+
+// checkLimits checks a request against the cached services list. The
+// service is looked up by serviceCurrency; the amount is only compared if
+// it is denominated in that currency. Fiat-priced requests without a
+// conversion target are not checked.
+func (c *Heleket) checkLimits(ctx context.Context, keyKind KeyKind, amountCurrency, serviceCurrency, network, amount string) error {
+	if c.services == nil || network == "" {
+		return nil
+	}
+	if info, ok := c.registry.Currency(serviceCurrency); ok && info.Fiat {
+		return nil
+	}
+
+	limits := c.services.limits(ctx, c, keyKind)
+	if limits == nil {
+		return nil
+	}
+
+	limitErr := &LimitError{KeyKind: keyKind, Currency: strings.ToUpper(serviceCurrency), Network: strings.ToLower(network)}
+	limit, ok := limits[servicePair{limitErr.Currency, limitErr.Network}]
+	if !ok || !limit.available {
+		limitErr.Unavailable = true
+		return limitErr
+	}
+	if !strings.EqualFold(amountCurrency, serviceCurrency) {
+		return nil
+	}
+
+	value := amountOf(amount)
+	if value.IsNull() {
+		return nil
+	}
+	if (!limit.min.IsNull() && value.LessThan(limit.min)) || (!limit.max.IsNull() && value.GreaterThan(limit.max)) {
+		limitErr.Amount, limitErr.Min, limitErr.Max = value, limit.min, limit.max
+		return limitErr
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package heleket
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const redacted = "[REDACTED]"
+
+// WithLogger logs every call attempt to logger: successful calls at Info,
+// failed calls at Warn, and redacted request and response bodies at Debug.
+// The sign field, API keys, payer emails and wallet addresses are never
+// logged in clear text.
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Heleket) {
+		c.middleware = append(c.middleware, c.loggingMiddleware(logger))
+	}
+}
+
+func (c *Heleket) loggingMiddleware(logger *slog.Logger) Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(ctx context.Context, req *Request) (*Response, error) {
+			start := time.Now()
+			res, err := next.Do(ctx, req)
+
+			attrs := []slog.Attr{
+				slog.String("endpoint", endpointPath(req.Endpoint)),
+				slog.String("key", req.KeyKind.String()),
+				slog.Int("attempt", req.Attempt),
+				slog.Duration("duration", time.Since(start)),
+			}
+			if res != nil {
+				attrs = append(attrs, slog.Int("status", res.StatusCode), slog.Int("state", int(res.State)))
+			}
+			ids := identifiers(req.Body)
+			if res != nil && err == nil {
+				resIds := identifiers(resultOf(res.Body))
+				if ids.OrderId == "" {
+					ids.OrderId = resIds.OrderId
+				}
+				if ids.UUID == "" {
+					ids.UUID = resIds.UUID
+				}
+			}
+			if ids.OrderId != "" {
+				attrs = append(attrs, slog.String("order_id", ids.OrderId))
+			}
+			if ids.UUID != "" {
+				attrs = append(attrs, slog.String("uuid", ids.UUID))
+			}
+
+			level := slog.LevelInfo
+			message := "heleket call"
+			if err != nil {
+				level = slog.LevelWarn
+				message = "heleket call failed"
+				attrs = append(attrs, slog.String("error", c.scrubKeys(err.Error())))
+			}
+			logger.LogAttrs(ctx, level, message, attrs...)
+
+			if logger.Enabled(ctx, slog.LevelDebug) {
+				debugAttrs := []slog.Attr{
+					slog.String("endpoint", endpointPath(req.Endpoint)),
+					slog.String("request", c.scrubKeys(string(RedactJSON(req.Body)))),
+				}
+				if res != nil {
+					debugAttrs = append(debugAttrs, slog.String("response", c.scrubKeys(string(RedactJSON(res.Body)))))
+				}
+				logger.LogAttrs(ctx, slog.LevelDebug, "heleket call bodies", debugAttrs...)
+			}
+
+			return res, err
+		})
+	}
+}
+
+// scrubKeys replaces any occurrence of the client's API keys in s.
+func (c *Heleket) scrubKeys(s string) string {
+	for _, key := range []string{c.paymentApiKey, c.payoutApiKey} {
+		if key != "" {
+			s = strings.ReplaceAll(s, key, redacted)
+		}
+	}
+	return s
+}
+
+type callIdentifiers struct {
+	OrderId string `json:"order_id"`
+	UUID    string `json:"uuid"`
+}
+
+func identifiers(body []byte) callIdentifiers {
+	var ids callIdentifiers
+	json.Unmarshal(body, &ids)
+	return ids
+}
+
+func resultOf(body []byte) []byte {
+	var envelope struct {
+		Result json.RawMessage `json:"result"`
+	}
+	json.Unmarshal(body, &envelope)
+	return envelope.Result
+}
+
+// redactedFields maps JSON keys to the function used to mask their values.
+var redactedFields = map[string]func(string) string{
+	"sign":        func(string) string { return redacted },
+	"payer_email": maskEmail,
+	"address":     maskAddress,
+	"from":        maskAddress,
+	"memo":        func(string) string { return redacted },
+}
+
+// RedactJSON returns a copy of a JSON request, response or webhook body with
+// the sign field, payer emails and wallet addresses masked, safe for logging.
+// Bodies that are not valid JSON are replaced entirely.
+func RedactJSON(body []byte) []byte {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return body
+	}
+
+	var value any
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return []byte(redacted)
+	}
+
+	out, err := json.Marshal(redactValue(value))
+	if err != nil {
+		return []byte(redacted)
+	}
+	return out
+}
+
+func redactValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			if mask, ok := redactedFields[key]; ok {
+				if s, isString := item.(string); isString && s != "" {
+					v[key] = mask(s)
+					continue
+				}
+			}
+			v[key] = redactValue(item)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = redactValue(item)
+		}
+	}
+	return value
+}
+
+// maskEmail keeps the first character of the local part and the domain.
+func maskEmail(email string) string {
+	local, domain, ok := strings.Cut(email, "@")
+	if !ok || local == "" {
+		return redacted
+	}
+	_, size := utf8.DecodeRuneInString(local)
+	return local[:size] + "***@" + domain
+}
+
+// maskAddress keeps the first and last four characters of a wallet address.
+func maskAddress(address string) string {
+	if len(address) <= 10 {
+		return redacted
+	}
+	return address[:4] + "..." + address[len(address)-4:]
+}
@@ -0,0 +1,219 @@
+package heleket
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MetricsCollector receives client metrics. Implementations must be safe for
+// concurrent use.
+type MetricsCollector interface {
+	// ObserveRequest is called once per call, after all attempts, with the
+	// endpoint path, the total duration and the ErrorClass of the outcome.
+	ObserveRequest(endpoint string, keyKind KeyKind, duration time.Duration, errorClass string)
+	// ObserveRetry is called before every retry of a call.
+	ObserveRetry(endpoint string, keyKind KeyKind)
+	// ObserveWebhookVerificationFailure is called when a webhook signature
+	// cannot be verified.
+	ObserveWebhookVerificationFailure()
+}
+
+// WithMetrics reports client metrics to collector.
+func WithMetrics(collector MetricsCollector) Option {
+	return func(c *Heleket) {
+		c.metrics = collector
+	}
+}
+
+// Error classes reported by ErrorClass.
+const (
+	ErrorClassNone              = ""
+	ErrorClassValidation        = "validation"
+	ErrorClassNotFound          = "not_found"
+	ErrorClassUnauthorized      = "unauthorized"
+	ErrorClassInsufficientFunds = "insufficient_funds"
+	ErrorClassRateLimited       = "rate_limited"
+	ErrorClassServer            = "server"
+	ErrorClassClient            = "client"
+	ErrorClassTimeout           = "timeout"
+	ErrorClassCanceled          = "canceled"
+	ErrorClassNetwork           = "network"
+	ErrorClassCircuitOpen       = "circuit_open"
+	ErrorClassOther             = "other"
+)
+
+// ErrorClass classifies an error returned by the client for metrics and
+// alerting. It returns ErrorClassNone for a nil error.
+func ErrorClass(err error) string {
+	if err == nil {
+		return ErrorClassNone
+	}
+
+	var apiErr *APIError
+	switch {
+	case errors.Is(err, ErrCircuitOpen):
+		return ErrorClassCircuitOpen
+	case errors.Is(err, context.Canceled):
+		return ErrorClassCanceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrorClassTimeout
+	case errors.Is(err, ErrRateLimited):
+		return ErrorClassRateLimited
+	case errors.Is(err, ErrUnauthorized):
+		return ErrorClassUnauthorized
+	case errors.Is(err, ErrValidation):
+		return ErrorClassValidation
+	case errors.Is(err, ErrNotFound):
+		return ErrorClassNotFound
+	case errors.Is(err, ErrInsufficientFunds):
+		return ErrorClassInsufficientFunds
+	case errors.As(err, &apiErr):
+		if apiErr.StatusCode >= 500 {
+			return ErrorClassServer
+		}
+		return ErrorClassClient
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		if netErr.Timeout() {
+			return ErrorClassTimeout
+		}
+		return ErrorClassNetwork
+	}
+	return ErrorClassOther
+}
+
+type noopMetrics struct{}
+
+func (noopMetrics) ObserveRequest(string, KeyKind, time.Duration, string) {}
+func (noopMetrics) ObserveRetry(string, KeyKind)                          {}
+func (noopMetrics) ObserveWebhookVerificationFailure()                    {}
+
+// ExpvarMetrics is a MetricsCollector that publishes its metrics through
+// expvar, so they show up on /debug/vars. The published map contains:
+//
+//	requests                        calls per endpoint
+//	errors                          failed calls per endpoint and error class
+//	latency_ms                      latency histogram per endpoint
+//	retries                         retries per endpoint
+//	webhook_verification_failures   failed webhook signature checks
+type ExpvarMetrics struct {
+	requests             *expvar.Map
+	errors               *expvar.Map
+	latency              *expvar.Map
+	retries              *expvar.Map
+	webhookVerifications *expvar.Int
+
+	mu sync.Mutex
+}
+
+// NewExpvarMetrics publishes a new expvar map under name. If a map with
+// that name is already published, it is reused.
+func NewExpvarMetrics(name string) *ExpvarMetrics {
+	root, ok := expvar.Get(name).(*expvar.Map)
+	if !ok {
+		root = expvar.NewMap(name)
+	}
+
+	m := &ExpvarMetrics{
+		requests:             new(expvar.Map),
+		errors:               new(expvar.Map),
+		latency:              new(expvar.Map),
+		retries:              new(expvar.Map),
+		webhookVerifications: new(expvar.Int),
+	}
+	root.Set("requests", m.requests)
+	root.Set("errors", m.errors)
+	root.Set("latency_ms", m.latency)
+	root.Set("retries", m.retries)
+	root.Set("webhook_verification_failures", m.webhookVerifications)
+	return m
+}
+
+func (m *ExpvarMetrics) ObserveRequest(endpoint string, keyKind KeyKind, duration time.Duration, errorClass string) {
+	m.requests.Add(endpoint, 1)
+	if errorClass != ErrorClassNone {
+		m.endpointErrors(endpoint).Add(errorClass, 1)
+	}
+	m.histogram(endpoint).observe(duration)
+}
+
+func (m *ExpvarMetrics) ObserveRetry(endpoint string, keyKind KeyKind) {
+	m.retries.Add(endpoint, 1)
+}
+
+func (m *ExpvarMetrics) ObserveWebhookVerificationFailure() {
+	m.webhookVerifications.Add(1)
+}
+
+func (m *ExpvarMetrics) endpointErrors(endpoint string) *expvar.Map {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	errs, ok := m.errors.Get(endpoint).(*expvar.Map)
+	if !ok {
+		errs = new(expvar.Map)
+		m.errors.Set(endpoint, errs)
+	}
+	return errs
+}
+
+func (m *ExpvarMetrics) histogram(endpoint string) *latencyHistogram {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	h, ok := m.latency.Get(endpoint).(*latencyHistogram)
+	if !ok {
+		h = newLatencyHistogram()
+		m.latency.Set(endpoint, h)
+	}
+	return h
+}
+
+// latencyBuckets are the upper bounds of the histogram buckets in milliseconds.
+var latencyBuckets = []float64{5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000}
+
+// latencyHistogram is a cumulative histogram that renders itself as JSON for expvar.
+type latencyHistogram struct {
+	mu     sync.Mutex
+	counts []int64
+	count  int64
+	sum    float64
+}
+
+func newLatencyHistogram() *latencyHistogram {
+	return &latencyHistogram{counts: make([]int64, len(latencyBuckets))}
+}
+
+func (h *latencyHistogram) observe(d time.Duration) {
+	ms := float64(d) / float64(time.Millisecond)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i, bound := range latencyBuckets {
+		if ms <= bound {
+			h.counts[i]++
+		}
+	}
+	h.count++
+	h.sum += ms
+}
+
+func (h *latencyHistogram) String() string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var b strings.Builder
+	b.WriteString(`{"buckets":{`)
+	for i, bound := range latencyBuckets {
+		fmt.Fprintf(&b, `"%s":%d,`, strconv.FormatFloat(bound, 'f', -1, 64), h.counts[i])
+	}
+	fmt.Fprintf(&b, `"+Inf":%d},"count":%d,"sum":%s}`, h.count, h.count, strconv.FormatFloat(h.sum, 'f', 3, 64))
+	return b.String()
+}
@@ -0,0 +1,70 @@
+package heleket
+
+import (
+	"context"
+	"net/http"
+)
+
+// Request is a single Heleket API call as seen by middleware.
+type Request struct {
+	// Method is the HTTP method, e.g. "POST".
+	Method string
+	// Endpoint is the path relative to the base URL, including any query
+	// string, e.g. "/payment/list?cursor=abc".
+	Endpoint string
+	// KeyKind is the API key the request is signed with.
+	KeyKind KeyKind
+	// Body is the marshalled JSON payload. It is signed after the middleware
+	// chain has run, so middleware may replace it.
+	Body []byte
+	// Header holds extra request headers. The merchant, sign and
+	// Content-Type headers are set when the request is sent.
+	Header http.Header
+	// Attempt is the 1-based attempt number when retries are enabled.
+	Attempt int
+}
+
+// Response is the outcome of a Heleket API call.
+type Response struct {
+	// StatusCode is the HTTP status code.
+	StatusCode int
+	// Header holds the response headers.
+	Header http.Header
+	// Body is the raw response body.
+	Body []byte
+	// State is the "state" value decoded from the body.
+	State int8
+}
+
+// Doer performs Heleket API calls. A failed call returns an *APIError
+// together with the Response that caused it.
+type Doer interface {
+	Do(ctx context.Context, req *Request) (*Response, error)
+}
+
+// DoerFunc adapts a function to the Doer interface.
+type DoerFunc func(ctx context.Context, req *Request) (*Response, error)
+
+func (f DoerFunc) Do(ctx context.Context, req *Request) (*Response, error) {
+	return f(ctx, req)
+}
+
+// Middleware wraps a Doer to observe or change calls, e.g. for logging,
+// tracing, metrics, header injection or fault injection.
+type Middleware func(next Doer) Doer
+
+// WithMiddleware adds middleware around every call attempt. The first
+// middleware is the outermost one.
+func WithMiddleware(middleware ...Middleware) Option {
+	return func(c *Heleket) {
+		c.middleware = append(c.middleware, middleware...)
+	}
+}
+
+// chain wraps doer with middleware so that middleware[0] runs first.
+func chain(doer Doer, middleware []Middleware) Doer {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		doer = middleware[i](doer)
+	}
+	return doer
+}
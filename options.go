@@ -0,0 +1,47 @@
+package heleket
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Option configures a Heleket client created by New.
+type Option func(*Heleket)
+
+// WithBaseURL overrides the API base URL, e.g. to target a local stub,
+// a proxy or a staging host. The URL should include the version prefix.
+func WithBaseURL(baseURL string) Option {
+	return func(c *Heleket) {
+		c.baseURL = strings.TrimRight(baseURL, "/")
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request.
+func WithUserAgent(userAgent string) Option {
+	return func(c *Heleket) {
+		c.userAgent = userAgent
+	}
+}
+
+// WithHTTPClient replaces the HTTP client passed to New.
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *Heleket) {
+		c.client = client
+	}
+}
+
+// WithDefaultTimeout bounds every call whose context has no deadline.
+func WithDefaultTimeout(timeout time.Duration) Option {
+	return func(c *Heleket) {
+		c.timeout = timeout
+	}
+}
+
+// WithHeader adds a header sent with every request. The merchant, sign and
+// Content-Type headers are always set by the client and cannot be overridden.
+func WithHeader(key, value string) Option {
+	return func(c *Heleket) {
+		c.headers.Add(key, value)
+	}
+}
@@ -1,7 +1,7 @@
 package heleket
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 )
 
@@ -67,14 +67,12 @@ type exchangeRateListRawResponse struct {
 }
 
 func (c *Heleket) GetBalance() (*BalanceInfo, error) {
-	res, err := c.fetch("POST", balanceEndpoint, make(map[string]any), c.paymentApiKey)
-	if err != nil {
-		return nil, err
-	}
-	defer res.Body.Close()
+	return c.GetBalanceContext(context.Background())
+}
 
+func (c *Heleket) GetBalanceContext(ctx context.Context) (*BalanceInfo, error) {
 	response := &balanceRawResponse{}
-	if err = json.NewDecoder(res.Body).Decode(response); err != nil {
+	if err := c.fetch(ctx, "POST", balanceEndpoint, make(map[string]any), PaymentKey, response); err != nil {
 		return nil, err
 	}
 
@@ -86,14 +84,12 @@ func (c *Heleket) GetBalance() (*BalanceInfo, error) {
 }
 
 func (c *Heleket) GetDiscountsList() ([]*Discount, error) {
-	res, err := c.fetch("POST", discountListEndpoint, make(map[string]any), c.paymentApiKey)
-	if err != nil {
-		return nil, err
-	}
-	defer res.Body.Close()
+	return c.GetDiscountsListContext(context.Background())
+}
 
+func (c *Heleket) GetDiscountsListContext(ctx context.Context) ([]*Discount, error) {
 	response := &discountListRawResponse{}
-	if err = json.NewDecoder(res.Body).Decode(response); err != nil {
+	if err := c.fetch(ctx, "POST", discountListEndpoint, make(map[string]any), PaymentKey, response); err != nil {
 		return nil, err
 	}
 
@@ -101,14 +97,16 @@ func (c *Heleket) GetDiscountsList() ([]*Discount, error) {
 }
 
 func (c *Heleket) SetDiscount(req *SetDiscountRequest) (*Discount, error) {
-	res, err := c.fetch("POST", discountSetEndpoint, req, c.paymentApiKey)
-	if err != nil {
+	return c.SetDiscountContext(context.Background(), req)
+}
+
+func (c *Heleket) SetDiscountContext(ctx context.Context, req *SetDiscountRequest) (*Discount, error) {
+	if err := req.validate(c.registry); err != nil {
 		return nil, err
 	}
-	defer res.Body.Close()
 
 	response := &setDiscountRawResponse{}
-	if err = json.NewDecoder(res.Body).Decode(response); err != nil {
+	if err := c.fetch(ctx, "POST", discountSetEndpoint, req, PaymentKey, response); err != nil {
 		return nil, err
 	}
 
@@ -116,16 +114,14 @@ func (c *Heleket) SetDiscount(req *SetDiscountRequest) (*Discount, error) {
 }
 
 func (c *Heleket) GetExchangeRates(currency string) ([]*ExchangeRate, error) {
+	return c.GetExchangeRatesContext(context.Background(), currency)
+}
+
+func (c *Heleket) GetExchangeRatesContext(ctx context.Context, currency string) ([]*ExchangeRate, error) {
 	endpoint := fmt.Sprintf(exchangeRateListEndpointFmt, currency)
 	// GET request with no body, so payload is nil
-	res, err := c.fetch("GET", endpoint, nil, c.paymentApiKey)
-	if err != nil {
-		return nil, err
-	}
-	defer res.Body.Close()
-
 	response := &exchangeRateListRawResponse{}
-	if err = json.NewDecoder(res.Body).Decode(response); err != nil {
+	if err := c.fetch(ctx, "GET", endpoint, nil, PaymentKey, response); err != nil {
 		return nil, err
 	}
 
@@ -1,7 +1,7 @@
 package heleket
 
 import (
-	"encoding/json"
+	"context"
 	"errors"
 	"net/url"
 	"time"
@@ -71,8 +71,8 @@ type Payment struct {
 	Address                 string          `json:"address,omitempty"`
 	From                    string          `json:"from,omitempty"`
 	TxId                    string          `json:"txid,omitempty"`
-	PaymentStatus           string          `json:"payment_status"`
-	Status                  string          `json:"status,omitempty"`
+	PaymentStatus           Status          `json:"payment_status"`
+	Status                  Status          `json:"status,omitempty"`
 	Url                     string          `json:"url"`
 	ExpiredAt               float64         `json:"expired_at"`
 	IsFinal                 bool            `json:"is_final"`
@@ -147,15 +147,25 @@ type paymentServiceListRawResponse struct {
 }
 
 func (c *Heleket) CreateInvoice(invoiceReq *InvoiceRequest) (*Payment, error) {
-	res, err := c.fetch("POST", createInvoiceEndpoit, invoiceReq, c.paymentApiKey)
-	if err != nil {
+	return c.CreateInvoiceContext(context.Background(), invoiceReq)
+}
+
+func (c *Heleket) CreateInvoiceContext(ctx context.Context, invoiceReq *InvoiceRequest) (*Payment, error) {
+	if err := invoiceReq.validate(c.registry); err != nil {
 		return nil, err
 	}
-
-	defer res.Body.Close()
+	if o := invoiceReq.InvoiceRequestOptions; o != nil {
+		serviceCurrency := invoiceReq.Currency
+		if o.ToCurrency != "" {
+			serviceCurrency = o.ToCurrency
+		}
+		if err := c.checkLimits(ctx, PaymentKey, invoiceReq.Currency, serviceCurrency, o.Network, invoiceReq.Amount); err != nil {
+			return nil, err
+		}
+	}
 
 	response := &invoiceRawResponse{}
-	if err = json.NewDecoder(res.Body).Decode(response); err != nil {
+	if err := c.fetch(ctx, "POST", createInvoiceEndpoit, invoiceReq, PaymentKey, response); err != nil {
 		return nil, err
 	}
 
@@ -163,16 +173,13 @@ func (c *Heleket) CreateInvoice(invoiceReq *InvoiceRequest) (*Payment, error) {
 }
 
 func (c *Heleket) GeneratePaymentQRCode(paymentUUID string) (string, error) {
-	payload := map[string]any{"merchant_payment_uuid": paymentUUID}
-	res, err := c.fetch("POST", generateInvoiceQRCodeEndpoint, payload, c.paymentApiKey)
-	if err != nil {
-		return "", err
-	}
-
-	defer res.Body.Close()
+	return c.GeneratePaymentQRCodeContext(context.Background(), paymentUUID)
+}
 
+func (c *Heleket) GeneratePaymentQRCodeContext(ctx context.Context, paymentUUID string) (string, error) {
+	payload := map[string]any{"merchant_payment_uuid": paymentUUID}
 	response := &paymentQRCodeRawResponse{}
-	if err = json.NewDecoder(res.Body).Decode(response); err != nil {
+	if err := c.fetch(ctx, "POST", generateInvoiceQRCodeEndpoint, payload, PaymentKey, response); err != nil {
 		return "", err
 	}
 
@@ -181,43 +188,39 @@ func (c *Heleket) GeneratePaymentQRCode(paymentUUID string) (string, error) {
 }
 
 func (c *Heleket) GetPaymentInfo(paymentInfoReq *PaymentInfoRequest) (*Payment, error) {
+	return c.GetPaymentInfoContext(context.Background(), paymentInfoReq)
+}
+
+func (c *Heleket) GetPaymentInfoContext(ctx context.Context, paymentInfoReq *PaymentInfoRequest) (*Payment, error) {
 	if paymentInfoReq.PaymentUUID == "" && paymentInfoReq.OrderId == "" {
 		return nil, errors.New("you should pass one of required values [PaymentUUID, OrderId]")
 	}
 
-	res, err := c.fetch("POST", paymentInfoEndpoint, paymentInfoReq, c.paymentApiKey)
-	if err != nil {
-		return nil, err
-	}
-
-	defer res.Body.Close()
-
 	response := &invoiceRawResponse{}
-	if err = json.NewDecoder(res.Body).Decode(response); err != nil {
+	if err := c.fetch(ctx, "POST", paymentInfoEndpoint, paymentInfoReq, PaymentKey, response); err != nil {
 		return nil, err
 	}
 
 	return response.Result, nil
 }
 
+// GetPaymentHistory returns one page of payments created between dateFrom and
+// dateTo. The bounds are converted to the WithAPILocation zone.
 func (c *Heleket) GetPaymentHistory(dateFrom, dateTo time.Time, cursor string) (*PaymentHistoryResponse, error) {
-	const timeFormat = "2006-01-02 15:04:05"
-	payload := map[string]any{"date_from": dateFrom.Format(timeFormat), "date_to": dateTo.Format(timeFormat)}
+	return c.GetPaymentHistoryContext(context.Background(), dateFrom, dateTo, cursor)
+}
+
+// GetPaymentHistoryContext is GetPaymentHistory with a context.
+func (c *Heleket) GetPaymentHistoryContext(ctx context.Context, dateFrom, dateTo time.Time, cursor string) (*PaymentHistoryResponse, error) {
+	payload := c.historyPayload(dateFrom, dateTo)
 
 	endpoint := paymentHistoryEndpoint
 	if cursor != "" {
 		endpoint += "?cursor=" + url.QueryEscape(cursor)
 	}
 
-	res, err := c.fetch("POST", endpoint, payload, c.paymentApiKey)
-	if err != nil {
-		return nil, err
-	}
-
-	defer res.Body.Close()
-
 	response := &paymentHistoryRawResponse{}
-	if err = json.NewDecoder(res.Body).Decode(response); err != nil {
+	if err := c.fetch(ctx, "POST", endpoint, payload, PaymentKey, response); err != nil {
 		return nil, err
 	}
 
@@ -229,15 +232,13 @@ func (c *Heleket) GetPaymentHistory(dateFrom, dateTo time.Time, cursor string) (
 }
 
 func (c *Heleket) GetPaymentServicesList() ([]*PaymentService, error) {
-	payload := make(map[string]any)
-	res, err := c.fetch("POST", paymentServicesListEndpoint, payload, c.paymentApiKey)
-	if err != nil {
-		return nil, err
-	}
-	defer res.Body.Close()
+	return c.GetPaymentServicesListContext(context.Background())
+}
 
+func (c *Heleket) GetPaymentServicesListContext(ctx context.Context) ([]*PaymentService, error) {
+	payload := make(map[string]any)
 	response := &paymentServiceListRawResponse{}
-	if err = json.NewDecoder(res.Body).Decode(response); err != nil {
+	if err := c.fetch(ctx, "POST", paymentServicesListEndpoint, payload, PaymentKey, response); err != nil {
 		return nil, err
 	}
 
@@ -1,7 +1,7 @@
 package heleket
 
 import (
-	"encoding/json"
+	"context"
 	"errors"
 	"net/url"
 	"time"
@@ -34,17 +34,19 @@ type PayoutRequestOptions struct {
 }
 
 type Payout struct {
-	UUID          string `json:"uuid"`
-	Amount        string `json:"amount"`
-	Currency      string `json:"currency"`
-	Network       string `json:"network"`
-	Address       string `json:"address"`
-	TxId          string `json:"txid"`
-	Status        string `json:"status"`
-	IsFinal       bool   `json:"is_final"`
-	Balance       string `json:"balance"`
-	PayerCurrency string `json:"payer_currency"`
-	PayerAmount   string `json:"payer_amount"`
+	UUID          string    `json:"uuid"`
+	Amount        string    `json:"amount"`
+	Currency      string    `json:"currency"`
+	Network       string    `json:"network"`
+	Address       string    `json:"address"`
+	TxId          string    `json:"txid"`
+	Status        Status    `json:"status"`
+	IsFinal       bool      `json:"is_final"`
+	Balance       string    `json:"balance"`
+	PayerCurrency string    `json:"payer_currency"`
+	PayerAmount   string    `json:"payer_amount"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
 }
 
 type payoutRawResponse struct {
@@ -102,14 +104,31 @@ type payoutServiceListRawResponse struct {
 }
 
 func (c *Heleket) CreatePayout(payoutReq *PayoutRequest) (*Payout, error) {
-	res, err := c.fetch("POST", createPayoutEndpoint, payoutReq, c.payoutApiKey)
-	if err != nil {
+	return c.CreatePayoutContext(context.Background(), payoutReq)
+}
+
+func (c *Heleket) CreatePayoutContext(ctx context.Context, payoutReq *PayoutRequest) (*Payout, error) {
+	if err := payoutReq.validate(c.registry); err != nil {
+		return nil, err
+	}
+
+	var memo string
+	serviceCurrency := payoutReq.Currency
+	if o := payoutReq.PayoutRequestOptions; o != nil {
+		memo = o.Memo
+		if o.ToCurrency != "" {
+			serviceCurrency = o.ToCurrency
+		}
+	}
+	if err := c.checkAddress(payoutReq.Network, payoutReq.Address, memo); err != nil {
+		return nil, err
+	}
+	if err := c.checkLimits(ctx, PayoutKey, payoutReq.Currency, serviceCurrency, payoutReq.Network, payoutReq.Amount); err != nil {
 		return nil, err
 	}
-	defer res.Body.Close()
 
 	response := &payoutRawResponse{}
-	if err = json.NewDecoder(res.Body).Decode(response); err != nil {
+	if err := c.fetch(ctx, "POST", createPayoutEndpoint, payoutReq, PayoutKey, response); err != nil {
 		return nil, err
 	}
 
@@ -117,41 +136,39 @@ func (c *Heleket) CreatePayout(payoutReq *PayoutRequest) (*Payout, error) {
 }
 
 func (c *Heleket) GetPayoutInfo(payoutInfoReq *PayoutInfoRequest) (*Payout, error) {
+	return c.GetPayoutInfoContext(context.Background(), payoutInfoReq)
+}
+
+func (c *Heleket) GetPayoutInfoContext(ctx context.Context, payoutInfoReq *PayoutInfoRequest) (*Payout, error) {
 	if payoutInfoReq.PayoutUUID == "" && payoutInfoReq.OrderId == "" {
 		return nil, errors.New("you should pass one of required values [PayoutUUID, OrderId]")
 	}
 
-	res, err := c.fetch("POST", payoutInfoEndpoint, payoutInfoReq, c.payoutApiKey)
-	if err != nil {
-		return nil, err
-	}
-	defer res.Body.Close()
-
 	response := &payoutRawResponse{}
-	if err = json.NewDecoder(res.Body).Decode(response); err != nil {
+	if err := c.fetch(ctx, "POST", payoutInfoEndpoint, payoutInfoReq, PayoutKey, response); err != nil {
 		return nil, err
 	}
 
 	return response.Result, nil
 }
 
+// GetPayoutHistory returns one page of payouts created between dateFrom and
+// dateTo. The bounds are converted to the WithAPILocation zone.
 func (c *Heleket) GetPayoutHistory(dateFrom, dateTo time.Time, cursor string) (*PayoutHistoryResponse, error) {
-	const timeFormat = "2006-01-02 15:04:05"
-	payload := map[string]any{"date_from": dateFrom.Format(timeFormat), "date_to": dateTo.Format(timeFormat)}
+	return c.GetPayoutHistoryContext(context.Background(), dateFrom, dateTo, cursor)
+}
+
+// GetPayoutHistoryContext is GetPayoutHistory with a context.
+func (c *Heleket) GetPayoutHistoryContext(ctx context.Context, dateFrom, dateTo time.Time, cursor string) (*PayoutHistoryResponse, error) {
+	payload := c.historyPayload(dateFrom, dateTo)
 
 	endpoint := payoutHistoryEndpoint
 	if cursor != "" {
 		endpoint += "?cursor=" + url.QueryEscape(cursor)
 	}
 
-	res, err := c.fetch("POST", endpoint, payload, c.payoutApiKey)
-	if err != nil {
-		return nil, err
-	}
-	defer res.Body.Close()
-
 	response := &payoutHistoryRawResponse{}
-	if err = json.NewDecoder(res.Body).Decode(response); err != nil {
+	if err := c.fetch(ctx, "POST", endpoint, payload, PayoutKey, response); err != nil {
 		return nil, err
 	}
 
@@ -164,15 +181,13 @@ func (c *Heleket) GetPayoutHistory(dateFrom, dateTo time.Time, cursor string) (*
 }
 
 func (c *Heleket) GetPayoutServicesList() ([]*PayoutService, error) {
-	payload := make(map[string]any)
-	res, err := c.fetch("POST", payoutServicesListEndpoint, payload, c.payoutApiKey)
-	if err != nil {
-		return nil, err
-	}
-	defer res.Body.Close()
+	return c.GetPayoutServicesListContext(context.Background())
+}
 
+func (c *Heleket) GetPayoutServicesListContext(ctx context.Context) ([]*PayoutService, error) {
+	payload := make(map[string]any)
 	response := &payoutServiceListRawResponse{}
-	if err = json.NewDecoder(res.Body).Decode(response); err != nil {
+	if err := c.fetch(ctx, "POST", payoutServicesListEndpoint, payload, PayoutKey, response); err != nil {
 		return nil, err
 	}
 
@@ -0,0 +1,192 @@
+package heleket
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EndpointGroup groups related endpoints that share a rate limit.
+type EndpointGroup string
+
+const (
+	GroupPayments EndpointGroup = "payments"
+	GroupPayouts  EndpointGroup = "payouts"
+	GroupHistory  EndpointGroup = "history"
+	GroupWallets  EndpointGroup = "wallets"
+	GroupRefunds  EndpointGroup = "refunds"
+	GroupWebhooks EndpointGroup = "webhooks"
+	GroupAccount  EndpointGroup = "account"
+)
+
+var endpointGroups = map[string]EndpointGroup{
+	createInvoiceEndpoit:               GroupPayments,
+	generateInvoiceQRCodeEndpoint:      GroupPayments,
+	paymentInfoEndpoint:                GroupPayments,
+	paymentServicesListEndpoint:        GroupPayments,
+	paymentHistoryEndpoint:             GroupHistory,
+	createPayoutEndpoint:               GroupPayouts,
+	payoutInfoEndpoint:                 GroupPayouts,
+	payoutServicesListEndpoint:         GroupPayouts,
+	payoutHistoryEndpoint:              GroupHistory,
+	createStaticWalletEndpoint:         GroupWallets,
+	generateStaticWalletQRCodeEndpoint: GroupWallets,
+	blockWalletAddressEndpoint:         GroupWallets,
+	refundEndpoint:                     GroupRefunds,
+	blockedAddressRefundEndpoint:       GroupRefunds,
+	resendWebhookEndpoint:              GroupWebhooks,
+	testPaymentWebhookEndpoint:         GroupWebhooks,
+	testPayoutWebhookEndpoint:          GroupWebhooks,
+	testWalletWebhookEndpoint:          GroupWebhooks,
+	balanceEndpoint:                    GroupAccount,
+	discountListEndpoint:               GroupAccount,
+	discountSetEndpoint:                GroupAccount,
+}
+
+// endpointPath strips the query string from an endpoint.
+func endpointPath(endpoint string) string {
+	path, _, _ := strings.Cut(endpoint, "?")
+	return path
+}
+
+// EndpointGroupOf returns the group an endpoint path such as "/payment/info" belongs to.
+func EndpointGroupOf(endpoint string) EndpointGroup {
+	path := endpointPath(endpoint)
+	if group, ok := endpointGroups[path]; ok {
+		return group
+	}
+	if strings.HasPrefix(path, "/exchange-rate/") {
+		return GroupAccount
+	}
+	return ""
+}
+
+// RateLimit describes a token bucket: Rate tokens are added per second up to Burst.
+// A zero Rate means no limit.
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// RateLimits configures the client-side rate limiter. A call has to pass the
+// limit of its API key and the limit of its endpoint group, if any.
+type RateLimits struct {
+	// Payment limits all calls signed with the payment API key.
+	Payment RateLimit
+	// Payout limits all calls signed with the payout API key.
+	Payout RateLimit
+	// Groups limits calls per endpoint group across both keys.
+	Groups map[EndpointGroup]RateLimit
+	// OnDelay, if set, is called whenever a call has to wait for the limiter.
+	OnDelay func(endpoint string, keyKind KeyKind, delay time.Duration)
+}
+
+// WithRateLimits enables the client-side rate limiter. Calls that exceed the
+// limits block until a token is available or their context is done.
+func WithRateLimits(limits RateLimits) Option {
+	return func(c *Heleket) {
+		c.limiter = newRateLimiter(limits)
+	}
+}
+
+type rateLimiter struct {
+	keys    map[KeyKind]*tokenBucket
+	groups  map[EndpointGroup]*tokenBucket
+	onDelay func(endpoint string, keyKind KeyKind, delay time.Duration)
+}
+
+func newRateLimiter(limits RateLimits) *rateLimiter {
+	l := &rateLimiter{
+		keys:    make(map[KeyKind]*tokenBucket),
+		groups:  make(map[EndpointGroup]*tokenBucket),
+		onDelay: limits.OnDelay,
+	}
+	if b := newTokenBucket(limits.Payment); b != nil {
+		l.keys[PaymentKey] = b
+	}
+	if b := newTokenBucket(limits.Payout); b != nil {
+		l.keys[PayoutKey] = b
+	}
+	for group, limit := range limits.Groups {
+		if b := newTokenBucket(limit); b != nil {
+			l.groups[group] = b
+		}
+	}
+	return l
+}
+
+// wait blocks until the call is allowed by every applicable bucket.
+func (l *rateLimiter) wait(ctx context.Context, endpoint string, keyKind KeyKind) error {
+	if l == nil {
+		return nil
+	}
+
+	buckets := make([]*tokenBucket, 0, 2)
+	if b := l.keys[keyKind]; b != nil {
+		buckets = append(buckets, b)
+	}
+	if b := l.groups[EndpointGroupOf(endpoint)]; b != nil {
+		buckets = append(buckets, b)
+	}
+
+	var delay time.Duration
+	now := time.Now()
+	for _, b := range buckets {
+		delay = max(delay, b.reserve(now))
+	}
+	if delay <= 0 {
+		return nil
+	}
+
+	if l.onDelay != nil {
+		l.onDelay(endpointPath(endpoint), keyKind, delay)
+	}
+	if err := sleep(ctx, delay); err != nil {
+		for _, b := range buckets {
+			b.release()
+		}
+		return err
+	}
+	return nil
+}
+
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(limit RateLimit) *tokenBucket {
+	if limit.Rate <= 0 {
+		return nil
+	}
+	burst := float64(max(limit.Burst, 1))
+	return &tokenBucket{rate: limit.Rate, burst: burst, tokens: burst, last: time.Now()}
+}
+
+// reserve takes a token and returns how long the caller has to wait before using it.
+func (b *tokenBucket) reserve(now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if now.After(b.last) {
+		b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+		b.last = now
+	}
+
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// release gives back a token reserved by a caller that gave up waiting.
+func (b *tokenBucket) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = min(b.burst, b.tokens+1)
+}
@@ -1,7 +1,7 @@
 package heleket
 
 import (
-	"encoding/json"
+	"context"
 	"errors"
 )
 
@@ -16,6 +16,11 @@ type RefundRequest struct {
 	PaymentUUID string `json:"uuid,omitempty"`
 	OrderId     string `json:"order_id,omitempty"`
 	Amount      string `json:"amount,omitempty"`
+
+	// Network, if set, is the network of the payment, used to validate
+	// Address locally. It is not sent to Heleket. When empty, Address only
+	// has to be valid on some known network.
+	Network string `json:"-"`
 }
 
 type refundRawResponse struct {
@@ -27,6 +32,11 @@ type BlockedAddressRefundRequest struct {
 	WalletUUID string `json:"uuid,omitempty"`
 	OrderId    string `json:"order_id,omitempty"`
 	Address    string `json:"address"`
+
+	// Network, if set, is the network of the wallet, used to validate
+	// Address locally. It is not sent to Heleket. When empty, Address only
+	// has to be valid on some known network.
+	Network string `json:"-"`
 }
 
 type BlockedAddressRefundResponse struct {
@@ -40,19 +50,28 @@ type blockedAddressRefundRawResponse struct {
 }
 
 func (c *Heleket) Refund(refundRequest *RefundRequest) (bool, error) {
-	if refundRequest.PaymentUUID == "" && refundRequest.OrderId == "" {
-		return false, errors.New("you should pass one of required values [PaymentUUID, OrderId]")
-	}
+	return c.RefundContext(context.Background(), refundRequest)
+}
 
-	res, err := c.fetch("POST", refundEndpoint, refundRequest, c.paymentApiKey)
-	if err != nil {
+func (c *Heleket) RefundContext(ctx context.Context, refundRequest *RefundRequest) (bool, error) {
+	if err := refundRequest.Validate(); err != nil {
 		return false, err
 	}
 
-	defer res.Body.Close()
+	if !c.skipAddressCheck {
+		var err error
+		if refundRequest.Network != "" {
+			err = c.checkAddress(refundRequest.Network, refundRequest.Address, "")
+		} else {
+			err = c.registry.ValidateAddressAny(refundRequest.Address)
+		}
+		if err != nil {
+			return false, err
+		}
+	}
 
 	response := &refundRawResponse{}
-	if err = json.NewDecoder(res.Body).Decode(response); err != nil {
+	if err := c.fetch(ctx, "POST", refundEndpoint, refundRequest, PaymentKey, response); err != nil {
 		return false, err
 	}
 
@@ -60,19 +79,28 @@ func (c *Heleket) Refund(refundRequest *RefundRequest) (bool, error) {
 }
 
 func (c *Heleket) BlockedAddressRefund(refundRequest *BlockedAddressRefundRequest) (*BlockedAddressRefundResponse, error) {
+	return c.BlockedAddressRefundContext(context.Background(), refundRequest)
+}
+
+func (c *Heleket) BlockedAddressRefundContext(ctx context.Context, refundRequest *BlockedAddressRefundRequest) (*BlockedAddressRefundResponse, error) {
 	if refundRequest.WalletUUID == "" && refundRequest.OrderId == "" {
 		return nil, errors.New("you should pass one of required values [WalletUUID, OrderId]")
 	}
 
-	res, err := c.fetch("POST", blockedAddressRefundEndpoint, refundRequest, c.paymentApiKey)
-	if err != nil {
-		return nil, err
+	if !c.skipAddressCheck {
+		var err error
+		if refundRequest.Network != "" {
+			err = c.checkAddress(refundRequest.Network, refundRequest.Address, "")
+		} else {
+			err = c.registry.ValidateAddressAny(refundRequest.Address)
+		}
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	defer res.Body.Close()
-
 	response := &blockedAddressRefundRawResponse{}
-	if err = json.NewDecoder(res.Body).Decode(response); err != nil {
+	if err := c.fetch(ctx, "POST", blockedAddressRefundEndpoint, refundRequest, PaymentKey, response); err != nil {
 		return nil, err
 	}
 
@@ -0,0 +1,502 @@
+package heleket
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrUnknownCurrency    = errors.New("heleket: unknown currency")
+	ErrUnknownNetwork     = errors.New("heleket: unknown network")
+	ErrUnsupportedNetwork = errors.New("heleket: currency is not supported on network")
+)
+
+// MemoRequirement tells whether transfers on a network carry a memo (tag).
+type MemoRequirement uint8
+
+const (
+	// MemoNone means the network has no memo field.
+	MemoNone MemoRequirement = iota
+	// MemoOptional means a memo may be set, e.g. to credit an exchange account.
+	MemoOptional
+	// MemoRequired means transfers without a memo are rejected or lost.
+	MemoRequired
+)
+
+func (m MemoRequirement) String() string {
+	switch m {
+	case MemoNone:
+		return "none"
+	case MemoOptional:
+		return "optional"
+	case MemoRequired:
+		return "required"
+	default:
+		return fmt.Sprintf("MemoRequirement(%d)", int(m))
+	}
+}
+
+// AddressValidator reports whether address is well-formed on a network.
+type AddressValidator func(address string) error
+
+// NetworkInfo describes a blockchain network as Heleket names it.
+type NetworkInfo struct {
+	// Code is the value of the network field in API requests, e.g. "tron".
+	Code string
+	// Name is a human-readable name, e.g. "Tron (TRC-20)".
+	Name string
+	// Memo tells whether transfers on the network carry a memo.
+	Memo MemoRequirement
+	// ExplorerTxURL is a block explorer URL with a {txid} placeholder.
+	ExplorerTxURL string
+	// ExplorerAddressURL is a block explorer URL with an {address} placeholder.
+	ExplorerAddressURL string
+	// ValidateAddress checks an address on the network. Nil accepts any address.
+	ValidateAddress AddressValidator
+}
+
+// TxURL returns the block explorer URL of the transaction txid, or "" if
+// the network has no explorer.
+func (n NetworkInfo) TxURL(txid string) string {
+	if n.ExplorerTxURL == "" || txid == "" {
+		return ""
+	}
+	return strings.ReplaceAll(n.ExplorerTxURL, "{txid}", txid)
+}
+
+// AddressURL returns the block explorer URL of address, or "" if the
+// network has no explorer.
+func (n NetworkInfo) AddressURL(address string) string {
+	if n.ExplorerAddressURL == "" || address == "" {
+		return ""
+	}
+	return strings.ReplaceAll(n.ExplorerAddressURL, "{address}", address)
+}
+
+// CurrencyInfo describes a currency Heleket accepts.
+type CurrencyInfo struct {
+	// Code is the currency code used in API requests, e.g. "USDT".
+	Code string
+	// Name is a human-readable name, e.g. "Tether".
+	Name string
+	// Precision is the number of fractional digits amounts are rendered with.
+	Precision int32
+	// Fiat is set for currencies that only price invoices and have no network.
+	Fiat bool
+	// Networks lists the codes of the networks the currency runs on.
+	Networks []string
+}
+
+// Supports reports whether the currency runs on network.
+func (c CurrencyInfo) Supports(network string) bool {
+	return slices.Contains(c.Networks, strings.ToLower(network))
+}
+
+// ServicesLister is the part of the client a Registry refreshes from.
+type ServicesLister interface {
+	GetPaymentServicesListContext(ctx context.Context) ([]*PaymentService, error)
+	GetPayoutServicesListContext(ctx context.Context) ([]*PayoutService, error)
+}
+
+// Registry holds the currencies and networks Heleket supports. It is safe
+// for concurrent use. NewRegistry returns one seeded with the built-in
+// entries; Refresh brings it in line with what is enabled for a merchant.
+type Registry struct {
+	mu         sync.RWMutex
+	currencies map[string]*CurrencyInfo
+	networks   map[string]*NetworkInfo
+	available  map[KeyKind]map[servicePair]bool
+}
+
+type servicePair struct {
+	currency string
+	network  string
+}
+
+// DefaultRegistry is the registry the SDK consults for currency precision
+// and network checks.
+var DefaultRegistry = NewRegistry()
+
+// WithRegistry sets the registry the client validates addresses and
+// networks against. The default is DefaultRegistry.
+func WithRegistry(registry *Registry) Option {
+	return func(c *Heleket) {
+		c.registry = registry
+	}
+}
+
+// NewRegistry returns a registry seeded with the built-in currencies and
+// networks.
+func NewRegistry() *Registry {
+	r := &Registry{
+		currencies: make(map[string]*CurrencyInfo, len(builtinCurrencies)),
+		networks:   make(map[string]*NetworkInfo, len(builtinNetworks)),
+		available:  map[KeyKind]map[servicePair]bool{},
+	}
+	for _, n := range builtinNetworks {
+		r.RegisterNetwork(n)
+	}
+	for _, c := range builtinCurrencies {
+		r.RegisterCurrency(c)
+	}
+	return r
+}
+
+// RegisterNetwork adds or replaces a network.
+func (r *Registry) RegisterNetwork(info NetworkInfo) {
+	info.Code = strings.ToLower(info.Code)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.networks[info.Code] = &info
+}
+
+// RegisterCurrency adds or replaces a currency.
+func (r *Registry) RegisterCurrency(info CurrencyInfo) {
+	info.Code = strings.ToUpper(info.Code)
+	info.Networks = normalizeNetworks(info.Networks)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.currencies[info.Code] = &info
+}
+
+func normalizeNetworks(networks []string) []string {
+	out := make([]string, 0, len(networks))
+	for _, n := range networks {
+		out = append(out, strings.ToLower(n))
+	}
+	slices.Sort(out)
+	return slices.Compact(out)
+}
+
+// Currency looks up a currency by code, ignoring case.
+func (r *Registry) Currency(code string) (CurrencyInfo, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	info, ok := r.currencies[strings.ToUpper(code)]
+	if !ok {
+		return CurrencyInfo{}, false
+	}
+	out := *info
+	out.Networks = slices.Clone(info.Networks)
+	return out, true
+}
+
+// Network looks up a network by code, ignoring case.
+func (r *Registry) Network(code string) (NetworkInfo, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	info, ok := r.networks[strings.ToLower(code)]
+	if !ok {
+		return NetworkInfo{}, false
+	}
+	return *info, true
+}
+
+// Currencies returns every registered currency, sorted by code.
+func (r *Registry) Currencies() []CurrencyInfo {
+	r.mu.RLock()
+	codes := slices.Sorted(maps.Keys(r.currencies))
+	r.mu.RUnlock()
+
+	out := make([]CurrencyInfo, 0, len(codes))
+	for _, code := range codes {
+		if info, ok := r.Currency(code); ok {
+			out = append(out, info)
+		}
+	}
+	return out
+}
+
+// Networks returns every registered network, sorted by code.
+func (r *Registry) Networks() []NetworkInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]NetworkInfo, 0, len(r.networks))
+	for _, code := range slices.Sorted(maps.Keys(r.networks)) {
+		out = append(out, *r.networks[code])
+	}
+	return out
+}
+
+// CheckCurrency returns an error wrapping ErrUnknownCurrency if code is
+// not registered.
+func (r *Registry) CheckCurrency(code string) error {
+	if _, ok := r.Currency(code); ok {
+		return nil
+	}
+	return fmt.Errorf("%w %q", ErrUnknownCurrency, code)
+}
+
+// CheckNetwork returns an error wrapping ErrUnknownNetwork if code is not
+// registered. The error suggests the Heleket code for common aliases such
+// as "trc20" or "erc20".
+func (r *Registry) CheckNetwork(code string) error {
+	if _, ok := r.Network(code); ok {
+		return nil
+	}
+	if alias, ok := networkAliases[strings.ToLower(code)]; ok {
+		if _, ok := r.Network(alias); ok {
+			return fmt.Errorf("%w %q, did you mean %q?", ErrUnknownNetwork, code, alias)
+		}
+	}
+	return fmt.Errorf("%w %q", ErrUnknownNetwork, code)
+}
+
+// CheckPair checks that currency and network are registered and that the
+// currency runs on the network. Fiat currencies are accepted with any
+// known network, since they only price an invoice paid in crypto.
+func (r *Registry) CheckPair(currency, network string) error {
+	info, ok := r.Currency(currency)
+	if !ok {
+		return fmt.Errorf("%w %q", ErrUnknownCurrency, currency)
+	}
+	if err := r.CheckNetwork(network); err != nil {
+		return err
+	}
+	if info.Fiat || info.Supports(network) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s is available on %s, not %q",
+		ErrUnsupportedNetwork, info.Code, strings.Join(info.Networks, ", "), network)
+}
+
+// Available reports whether the currency/network pair was listed as
+// available in the last refresh for the given key kind. known is false
+// if the pair was not listed or the registry was never refreshed.
+func (r *Registry) Available(keyKind KeyKind, currency, network string) (available, known bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	available, known = r.available[keyKind][servicePair{strings.ToUpper(currency), strings.ToLower(network)}]
+	return available, known
+}
+
+// listedNetworks returns the networks the last refresh for keyKind listed
+// for currency, available or not, sorted. It is empty if the currency was
+// not listed or the registry was never refreshed.
+func (r *Registry) listedNetworks(keyKind KeyKind, currency string) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	currency = strings.ToUpper(currency)
+	var networks []string
+	for pair := range r.available[keyKind] {
+		if pair.currency == currency {
+			networks = append(networks, pair.network)
+		}
+	}
+	slices.Sort(networks)
+	return networks
+}
+
+// Refresh updates the registry from the merchant's payment and payout
+// services lists. Currencies and networks Heleket reports but the registry
+// does not know are registered with default metadata: networks get no
+// address validator and an optional memo. The two lists are applied
+// independently; errors from either are joined.
+func (r *Registry) Refresh(ctx context.Context, client ServicesLister) error {
+	var errs []error
+
+	if services, err := client.GetPaymentServicesListContext(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("payment services: %w", err))
+	} else {
+		pairs := make([]serviceEntry, 0, len(services))
+		for _, s := range services {
+			pairs = append(pairs, serviceEntry{s.Currency, s.Network, s.IsAvailable})
+		}
+		r.applyServices(PaymentKey, pairs)
+	}
+
+	if services, err := client.GetPayoutServicesListContext(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("payout services: %w", err))
+	} else {
+		pairs := make([]serviceEntry, 0, len(services))
+		for _, s := range services {
+			pairs = append(pairs, serviceEntry{s.Currency, s.Network, s.IsAvailable})
+		}
+		r.applyServices(PayoutKey, pairs)
+	}
+
+	return errors.Join(errs...)
+}
+
+type serviceEntry struct {
+	currency    string
+	network     string
+	isAvailable bool
+}
+
+func (r *Registry) applyServices(keyKind KeyKind, services []serviceEntry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	available := make(map[servicePair]bool, len(services))
+	for _, s := range services {
+		pair := servicePair{strings.ToUpper(s.currency), strings.ToLower(s.network)}
+		available[pair] = available[pair] || s.isAvailable
+
+		if _, ok := r.networks[pair.network]; !ok {
+			// Nothing is known about the memo of a listed network, so
+			// memos are neither required nor rejected locally.
+			r.networks[pair.network] = &NetworkInfo{Code: pair.network, Name: pair.network, Memo: MemoOptional}
+		}
+		currency, ok := r.currencies[pair.currency]
+		if !ok {
+			currency = &CurrencyInfo{Code: pair.currency, Name: pair.currency, Precision: defaultPrecision}
+			r.currencies[pair.currency] = currency
+		}
+		if !slices.Contains(currency.Networks, pair.network) {
+			currency.Networks = normalizeNetworks(append(slices.Clone(currency.Networks), pair.network))
+		}
+	}
+	r.available[keyKind] = available
+}
+
+// networkAliases maps names commonly used for Heleket networks elsewhere
+// to the codes the API expects.
+var networkAliases = map[string]string{
+	"trc20":        "tron",
+	"trc-20":       "tron",
+	"trx":          "tron",
+	"erc20":        "eth",
+	"erc-20":       "eth",
+	"ethereum":     "eth",
+	"bep20":        "bsc",
+	"bep-20":       "bsc",
+	"bnb":          "bsc",
+	"binance":      "bsc",
+	"matic":        "polygon",
+	"pol":          "polygon",
+	"bitcoin":      "btc",
+	"litecoin":     "ltc",
+	"dogecoin":     "doge",
+	"bitcoincash":  "bch",
+	"bitcoin-cash": "bch",
+	"solana":       "sol",
+	"spl":          "sol",
+	"monero":       "xmr",
+	"arb":          "arbitrum",
+	"avax":         "avalanche",
+	"avaxc":        "avalanche",
+	"avax-c":       "avalanche",
+	"toncoin":      "ton",
+	"jetton":       "ton",
+}
+
+var builtinNetworks = []NetworkInfo{
+	{
+		Code: "tron", Name: "Tron (TRC-20)",
+		ExplorerTxURL:      "https://tronscan.org/#/transaction/{txid}",
+		ExplorerAddressURL: "https://tronscan.org/#/address/{address}",
+		ValidateAddress:    validateTronAddress,
+	},
+	{
+		Code: "eth", Name: "Ethereum (ERC-20)",
+		ExplorerTxURL:      "https://etherscan.io/tx/{txid}",
+		ExplorerAddressURL: "https://etherscan.io/address/{address}",
+		ValidateAddress:    validateEVMAddress,
+	},
+	{
+		Code: "bsc", Name: "BNB Smart Chain (BEP-20)",
+		ExplorerTxURL:      "https://bscscan.com/tx/{txid}",
+		ExplorerAddressURL: "https://bscscan.com/address/{address}",
+		ValidateAddress:    validateEVMAddress,
+	},
+	{
+		Code: "polygon", Name: "Polygon",
+		ExplorerTxURL:      "https://polygonscan.com/tx/{txid}",
+		ExplorerAddressURL: "https://polygonscan.com/address/{address}",
+		ValidateAddress:    validateEVMAddress,
+	},
+	{
+		Code: "arbitrum", Name: "Arbitrum One",
+		ExplorerTxURL:      "https://arbiscan.io/tx/{txid}",
+		ExplorerAddressURL: "https://arbiscan.io/address/{address}",
+		ValidateAddress:    validateEVMAddress,
+	},
+	{
+		Code: "avalanche", Name: "Avalanche C-Chain",
+		ExplorerTxURL:      "https://snowtrace.io/tx/{txid}",
+		ExplorerAddressURL: "https://snowtrace.io/address/{address}",
+		ValidateAddress:    validateEVMAddress,
+	},
+	{
+		Code: "btc", Name: "Bitcoin",
+		ExplorerTxURL:      "https://mempool.space/tx/{txid}",
+		ExplorerAddressURL: "https://mempool.space/address/{address}",
+		ValidateAddress:    validateBitcoinAddress,
+	},
+	{
+		Code: "ltc", Name: "Litecoin",
+		ExplorerTxURL:      "https://blockchair.com/litecoin/transaction/{txid}",
+		ExplorerAddressURL: "https://blockchair.com/litecoin/address/{address}",
+		ValidateAddress:    validateLitecoinAddress,
+	},
+	{
+		Code: "doge", Name: "Dogecoin",
+		ExplorerTxURL:      "https://blockchair.com/dogecoin/transaction/{txid}",
+		ExplorerAddressURL: "https://blockchair.com/dogecoin/address/{address}",
+		ValidateAddress:    validateDogecoinAddress,
+	},
+	{
+		Code: "bch", Name: "Bitcoin Cash",
+		ExplorerTxURL:      "https://blockchair.com/bitcoin-cash/transaction/{txid}",
+		ExplorerAddressURL: "https://blockchair.com/bitcoin-cash/address/{address}",
+		ValidateAddress:    validateBitcoinCashAddress,
+	},
+	{
+		Code: "dash", Name: "Dash",
+		ExplorerTxURL:      "https://blockchair.com/dash/transaction/{txid}",
+		ExplorerAddressURL: "https://blockchair.com/dash/address/{address}",
+		ValidateAddress:    validateDashAddress,
+	},
+	{
+		Code: "ton", Name: "TON", Memo: MemoOptional,
+		ExplorerTxURL:      "https://tonviewer.com/transaction/{txid}",
+		ExplorerAddressURL: "https://tonviewer.com/{address}",
+		ValidateAddress:    validateTONAddress,
+	},
+	{
+		Code: "sol", Name: "Solana",
+		ExplorerTxURL:      "https://solscan.io/tx/{txid}",
+		ExplorerAddressURL: "https://solscan.io/account/{address}",
+		ValidateAddress:    validateSolanaAddress,
+	},
+	{
+		Code: "xmr", Name: "Monero",
+		ExplorerTxURL:   "https://xmrchain.net/tx/{txid}",
+		ValidateAddress: validateMoneroAddress,
+	},
+}
+
+var builtinCurrencies = []CurrencyInfo{
+	{Code: "USD", Name: "US Dollar", Precision: 2, Fiat: true},
+	{Code: "EUR", Name: "Euro", Precision: 2, Fiat: true},
+	{Code: "GBP", Name: "Pound Sterling", Precision: 2, Fiat: true},
+	{Code: "RUB", Name: "Russian Ruble", Precision: 2, Fiat: true},
+	{Code: "UAH", Name: "Ukrainian Hryvnia", Precision: 2, Fiat: true},
+	{Code: "KZT", Name: "Kazakhstani Tenge", Precision: 2, Fiat: true},
+	{Code: "BRL", Name: "Brazilian Real", Precision: 2, Fiat: true},
+	{Code: "INR", Name: "Indian Rupee", Precision: 2, Fiat: true},
+	{Code: "TRY", Name: "Turkish Lira", Precision: 2, Fiat: true},
+	{Code: "USDT", Name: "Tether", Precision: 6, Networks: []string{"tron", "eth", "bsc", "polygon", "arbitrum", "avalanche", "ton", "sol"}},
+	{Code: "USDC", Name: "USD Coin", Precision: 6, Networks: []string{"eth", "bsc", "polygon", "arbitrum", "avalanche", "sol"}},
+	{Code: "DAI", Name: "Dai", Precision: 8, Networks: []string{"eth", "bsc", "polygon"}},
+	{Code: "TRX", Name: "Tron", Precision: 6, Networks: []string{"tron"}},
+	{Code: "BTC", Name: "Bitcoin", Precision: 8, Networks: []string{"btc"}},
+	{Code: "ETH", Name: "Ethereum", Precision: 8, Networks: []string{"eth", "arbitrum"}},
+	{Code: "BNB", Name: "BNB", Precision: 8, Networks: []string{"bsc"}},
+	{Code: "POL", Name: "Polygon", Precision: 8, Networks: []string{"polygon"}},
+	{Code: "AVAX", Name: "Avalanche", Precision: 8, Networks: []string{"avalanche"}},
+	{Code: "LTC", Name: "Litecoin", Precision: 8, Networks: []string{"ltc"}},
+	{Code: "DOGE", Name: "Dogecoin", Precision: 8, Networks: []string{"doge"}},
+	{Code: "BCH", Name: "Bitcoin Cash", Precision: 8, Networks: []string{"bch"}},
+	{Code: "DASH", Name: "Dash", Precision: 8, Networks: []string{"dash"}},
+	{Code: "TON", Name: "Toncoin", Precision: 8, Networks: []string{"ton"}},
+	{Code: "SOL", Name: "Solana", Precision: 8, Networks: []string{"sol"}},
+	{Code: "XMR", Name: "Monero", Precision: 8, Networks: []string{"xmr"}},
+	{Code: "SHIB", Name: "Shiba Inu", Precision: 8, Networks: []string{"eth"}},
+}
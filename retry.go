@@ -0,0 +1,158 @@
+package heleket
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy controls how failed calls are retried.
+//
+// Only transient failures are retried: connection resets, network timeouts,
+// HTTP 429 and HTTP 5xx responses. Calls that only read data (info, list,
+// services, balance, exchange rates, QR codes) are retried whenever the
+// policy is enabled. Calls that create or change something are retried only
+// when RetryMutating is set and the request carries an order_id, which
+// Heleket uses to recognise repeated submissions of the same operation.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	// Values below 2 disable retries.
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between attempts, including Retry-After.
+	MaxBackoff time.Duration
+	// RetryMutating opts in to retrying mutating calls that carry an order_id.
+	RetryMutating bool
+}
+
+// DefaultRetryPolicy returns a policy with three attempts and a backoff
+// starting at 200ms and capped at 5s.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 200 * time.Millisecond,
+		MaxBackoff:     5 * time.Second,
+	}
+}
+
+// WithRetryPolicy enables automatic retries of transient failures.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Heleket) {
+		c.retry = policy
+	}
+}
+
+// idempotentEndpoints lists the POST endpoints that are safe to repeat.
+// GET requests are always considered safe.
+var idempotentEndpoints = map[string]bool{
+	generateInvoiceQRCodeEndpoint:      true,
+	paymentInfoEndpoint:                true,
+	paymentHistoryEndpoint:             true,
+	paymentServicesListEndpoint:        true,
+	payoutInfoEndpoint:                 true,
+	payoutHistoryEndpoint:              true,
+	payoutServicesListEndpoint:         true,
+	generateStaticWalletQRCodeEndpoint: true,
+	balanceEndpoint:                    true,
+	discountListEndpoint:               true,
+}
+
+// allows reports whether a call may be retried at all.
+func (p RetryPolicy) allows(method, endpoint string, body []byte) bool {
+	if p.MaxAttempts < 2 {
+		return false
+	}
+
+	if method == http.MethodGet || idempotentEndpoints[endpointPath(endpoint)] {
+		return true
+	}
+
+	if !p.RetryMutating {
+		return false
+	}
+
+	var keyed struct {
+		OrderId string `json:"order_id"`
+	}
+	return json.Unmarshal(body, &keyed) == nil && keyed.OrderId != ""
+}
+
+// retryable reports whether the error of an attempt is a transient failure.
+func retryable(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusTooManyRequests || apiErr.StatusCode >= 500
+	}
+
+	var netErr net.Error
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, io.EOF) ||
+		(errors.As(err, &netErr) && netErr.Timeout())
+}
+
+// delay returns how long to wait before the given retry (1 for the first retry).
+func (p RetryPolicy) delay(retry int, res *Response) time.Duration {
+	if res != nil {
+		if after, ok := parseRetryAfter(res.Header.Get("Retry-After")); ok {
+			if p.MaxBackoff > 0 && after > p.MaxBackoff {
+				return p.MaxBackoff
+			}
+			return after
+		}
+	}
+
+	backoff := p.InitialBackoff
+	for i := 1; i < retry && (p.MaxBackoff <= 0 || backoff < p.MaxBackoff); i++ {
+		backoff *= 2
+	}
+	if p.MaxBackoff > 0 && backoff > p.MaxBackoff {
+		backoff = p.MaxBackoff
+	}
+	if backoff <= 0 {
+		return 0
+	}
+
+	// Equal jitter: half fixed, half random, so concurrent callers spread out.
+	return backoff/2 + rand.N(backoff/2+1)
+}
+
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(time.Until(at), 0), true
+	}
+	return 0, false
+}
+
+// sleep waits for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -1,6 +1,7 @@
 package heleket
 
 import (
+	"context"
 	"crypto/md5"
 	"encoding/base64"
 	"encoding/hex"
@@ -35,6 +36,21 @@ func (c *Heleket) signRequest(apiKey string, reqBody []byte) string {
 // unset($data['sign']);
 // $hash = md5(base64_encode(json_encode($data, JSON_UNESCAPED_UNICODE)) . $apiPaymentKey);
 func (c *Heleket) VerifySign(apiKey string, reqBody []byte) error {
+	return c.VerifySignContext(context.Background(), apiKey, reqBody)
+}
+
+// VerifySignContext is VerifySign with a context for tracing.
+func (c *Heleket) VerifySignContext(ctx context.Context, apiKey string, reqBody []byte) (err error) {
+	_, span := c.tracer.Start(ctx, "heleket.VerifySign")
+	defer func() {
+		if err != nil {
+			c.metrics.ObserveWebhookVerificationFailure()
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+	span.SetAttributes(Attribute{AttrMerchant, c.merchant})
+
 	// First, parse to extract the signature value
 	var jsonBody map[string]any
 	if err := json.Unmarshal(reqBody, &jsonBody); err != nil {
@@ -1,7 +1,7 @@
 package heleket
 
 import (
-	"encoding/json"
+	"context"
 	"errors"
 )
 
@@ -62,15 +62,16 @@ type blockAddressRawResponse struct {
 }
 
 func (c *Heleket) CreateStaticWallet(staticWalletReq *StaticWalletRequest) (*StaticWalletResponse, error) {
-	res, err := c.fetch("POST", createStaticWalletEndpoint, staticWalletReq, c.paymentApiKey)
-	if err != nil {
+	return c.CreateStaticWalletContext(context.Background(), staticWalletReq)
+}
+
+func (c *Heleket) CreateStaticWalletContext(ctx context.Context, staticWalletReq *StaticWalletRequest) (*StaticWalletResponse, error) {
+	if err := staticWalletReq.validate(c.registry); err != nil {
 		return nil, err
 	}
 
-	defer res.Body.Close()
-
 	response := &staticWalletRawResponse{}
-	if err = json.NewDecoder(res.Body).Decode(response); err != nil {
+	if err := c.fetch(ctx, "POST", createStaticWalletEndpoint, staticWalletReq, PaymentKey, response); err != nil {
 		return nil, err
 	}
 
@@ -78,16 +79,13 @@ func (c *Heleket) CreateStaticWallet(staticWalletReq *StaticWalletRequest) (*Sta
 }
 
 func (c *Heleket) GenerateStaticWalletQRCode(walletUUID string) (string, error) {
-	payload := map[string]any{"wallet_address_uuid": walletUUID}
-	res, err := c.fetch("POST", generateStaticWalletQRCodeEndpoint, payload, c.paymentApiKey)
-	if err != nil {
-		return "", err
-	}
-
-	defer res.Body.Close()
+	return c.GenerateStaticWalletQRCodeContext(context.Background(), walletUUID)
+}
 
+func (c *Heleket) GenerateStaticWalletQRCodeContext(ctx context.Context, walletUUID string) (string, error) {
+	payload := map[string]any{"wallet_address_uuid": walletUUID}
 	response := &staticWalletQRCodeRawResponse{}
-	if err = json.NewDecoder(res.Body).Decode(response); err != nil {
+	if err := c.fetch(ctx, "POST", generateStaticWalletQRCodeEndpoint, payload, PaymentKey, response); err != nil {
 		return "", err
 	}
 
@@ -95,19 +93,16 @@ func (c *Heleket) GenerateStaticWalletQRCode(walletUUID string) (string, error)
 }
 
 func (c *Heleket) BlockAddress(blockAddressReq *BlockAddressRequest) (*BlockAddressResponse, error) {
+	return c.BlockAddressContext(context.Background(), blockAddressReq)
+}
+
+func (c *Heleket) BlockAddressContext(ctx context.Context, blockAddressReq *BlockAddressRequest) (*BlockAddressResponse, error) {
 	if blockAddressReq.WalletUUID == "" && blockAddressReq.OrderId == "" {
 		return nil, errors.New("you should pass one of required values [WalletUUID, OrderId]")
 	}
 
-	res, err := c.fetch("POST", blockWalletAddressEndpoint, blockAddressReq, c.paymentApiKey)
-	if err != nil {
-		return nil, err
-	}
-
-	defer res.Body.Close()
-
 	response := &blockAddressRawResponse{}
-	if err = json.NewDecoder(res.Body).Decode(response); err != nil {
+	if err := c.fetch(ctx, "POST", blockWalletAddressEndpoint, blockAddressReq, PaymentKey, response); err != nil {
 		return nil, err
 	}
 
@@ -0,0 +1,97 @@
+package heleket
+
+// Status is the status of a payment or payout as reported by Heleket in
+// Payment.PaymentStatus, Payment.Status, Payout.Status and Webhook.Status.
+//
+// Statuses Heleket introduces after this SDK was released are preserved
+// as-is; IsKnown reports false for them and every classification method
+// returns false.
+type Status string
+
+// Payment statuses. Payouts use the subset process, check, paid, fail,
+// cancel and system_fail.
+const (
+	StatusPaid               Status = "paid"
+	StatusPaidOver           Status = "paid_over"
+	StatusWrongAmount        Status = "wrong_amount"
+	StatusProcess            Status = "process"
+	StatusConfirmCheck       Status = "confirm_check"
+	StatusWrongAmountWaiting Status = "wrong_amount_waiting"
+	StatusCheck              Status = "check"
+	StatusFail               Status = "fail"
+	StatusCancel             Status = "cancel"
+	StatusSystemFail         Status = "system_fail"
+	StatusRefundProcess      Status = "refund_process"
+	StatusRefundFail         Status = "refund_fail"
+	StatusRefundPaid         Status = "refund_paid"
+	StatusLocked             Status = "locked"
+)
+
+type statusClass uint8
+
+const (
+	classFinal statusClass = 1 << iota
+	classSuccessful
+	classRefund
+	classRequiresAction
+)
+
+var statusClasses = map[Status]statusClass{
+	StatusPaid:               classFinal | classSuccessful,
+	StatusPaidOver:           classFinal | classSuccessful,
+	StatusWrongAmount:        classFinal | classRequiresAction,
+	StatusProcess:            0,
+	StatusConfirmCheck:       0,
+	StatusWrongAmountWaiting: 0,
+	StatusCheck:              0,
+	StatusFail:               classFinal,
+	StatusCancel:             classFinal,
+	StatusSystemFail:         classFinal | classRequiresAction,
+	StatusRefundProcess:      classRefund,
+	StatusRefundFail:         classFinal | classRefund | classRequiresAction,
+	StatusRefundPaid:         classFinal | classRefund,
+	StatusLocked:             classRequiresAction,
+}
+
+func (s Status) String() string { return string(s) }
+
+// IsKnown reports whether s is one of the statuses documented by Heleket.
+func (s Status) IsKnown() bool {
+	_, ok := statusClasses[s]
+	return ok
+}
+
+// IsFinal reports whether s is terminal and will not change again.
+func (s Status) IsFinal() bool { return statusClasses[s]&classFinal != 0 }
+
+// IsSuccessful reports whether the payment or payout completed and the
+// funds were received or sent in full.
+func (s Status) IsSuccessful() bool { return statusClasses[s]&classSuccessful != 0 }
+
+// IsRefund reports whether s belongs to the refund flow.
+func (s Status) IsRefund() bool { return statusClasses[s]&classRefund != 0 }
+
+// RequiresAction reports whether s needs the merchant's attention: an
+// underpaid invoice, a system failure, a failed refund or funds locked
+// by an AML check.
+func (s Status) RequiresAction() bool { return statusClasses[s]&classRequiresAction != 0 }
+
+// WebhookType is the kind of object a webhook reports on.
+type WebhookType string
+
+const (
+	WebhookTypePayment WebhookType = "payment"
+	WebhookTypePayout  WebhookType = "payout"
+	WebhookTypeWallet  WebhookType = "wallet"
+)
+
+func (t WebhookType) String() string { return string(t) }
+
+// IsKnown reports whether t is one of the webhook types documented by Heleket.
+func (t WebhookType) IsKnown() bool {
+	switch t {
+	case WebhookTypePayment, WebhookTypePayout, WebhookTypeWallet:
+		return true
+	}
+	return false
+}
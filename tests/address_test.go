@@ -0,0 +1,162 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/idanyas/heleket-go"
+	"github.com/idanyas/heleket-go/heleketfake"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateAddress(t *testing.T) {
+	valid := map[string][]string{
+		"tron": {"TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t", "TXLAQ63Xg1NAzckPwKHvzw7CSEmLMEqcdj"},
+		"eth": {
+			"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+			"0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359",
+			"0xdbF03B407c01E7cD3CBea99509d93f8DDDC8C6FB",
+			"0xd1220a0cf47c7b9be7a2e6ba89f429762e7b9adb",
+		},
+		"bsc": {"0xD1220A0cf47c7B9Be7A2E6BA89F429762e7b9aDb"},
+		"btc": {
+			"1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa",
+			"3J98t1WpEZ73CNmQviecrnyiWrnqRhWNLy",
+			"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4",
+			"BC1QW508D6QEJXTDG4Y5R3ZARVARY0C5XW7KV8F3T4",
+			"bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqzk5jj0",
+		},
+		"ltc":  {"LKKHMBjCU89fyFNgSRprDoD8Jb25N8uWvd", "M7zVKQKmtV5Rc7erVGVVC3khZbXxsS5HEX", "ltc1qqypqxpq9qcrsszg2pvxq6rs0zqg3yyc5dyg36p"},
+		"doge": {"D5ERdEN1gsouFSs7zsq7VYJxyWP6dP28H1"},
+		"dash": {"XanAvE5GMB8CsPH78B9moJq9viEVKvCS4f"},
+		"bch":  {"bitcoincash:qpm2qsznhks23z7629mms6s4cwef74vcwvy22gdx6a", "qpm2qsznhks23z7629mms6s4cwef74vcwvy22gdx6a"},
+		"ton":  {"EQCD39VS5jcptHL8vMjEXrzGaRcCVYto7HUn4bpAOg8xqB2N", "0:83dfd552e63729b472fcbcc8c45ebcc6691702558b68ec7527e1ba403a0f31a8"},
+		"sol":  {"So11111111111111111111111111111111111111112"},
+	}
+	for network, addresses := range valid {
+		for _, address := range addresses {
+			require.NoError(t, heleket.ValidateAddress(network, address), "%s %s", network, address)
+		}
+	}
+
+	invalid := map[string][]string{
+		"tron": {"TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6u", "T1", "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"},
+		"eth":  {"0x5aaeb6053F3E94C9b9A09f33669435E7Ef1BeAed", "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAe", "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t"},
+		"btc":  {"1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNb", "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t5", "bc1qw508d6qejxtdg4y5r3zArvary0c5xw7kv8f3t4", "LKKHMBjCU89fyFNgSRprDoD8Jb25N8uWvd"},
+		"ltc":  {"1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa", "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4"},
+		"bch":  {"bitcoincash:qpm2qsznhks23z7629mms6s4cwef74vcwvy22gdx6b"},
+		"ton":  {"EQCD39VS5jcptHL8vMjEXrzGaRcCVYto7HUn4bpAOg8xqB2M"},
+		"sol":  {"So1111111111111111111111111111111111111111l"},
+	}
+	for network, addresses := range invalid {
+		for _, address := range addresses {
+			require.ErrorIs(t, heleket.ValidateAddress(network, address), heleket.ErrInvalidAddress, "%s %s", network, address)
+		}
+	}
+
+	require.NoError(t, heleket.ValidateAddress("somechain", "anything"))
+}
+
+func TestValidateMemo(t *testing.T) {
+	registry := heleket.NewRegistry()
+	registry.RegisterNetwork(heleket.NetworkInfo{Code: "xrp", Memo: heleket.MemoRequired})
+
+	require.NoError(t, registry.ValidateMemo("ton", "exchange-tag"))
+	require.NoError(t, registry.ValidateMemo("ton", ""))
+	require.ErrorIs(t, registry.ValidateMemo("tron", "tag"), heleket.ErrInvalidMemo)
+	require.ErrorIs(t, registry.ValidateMemo("xrp", ""), heleket.ErrInvalidMemo)
+}
+
+func TestPayoutAddressCheckedBeforeSending(t *testing.T) {
+	var calls atomic.Int32
+	client := newStubHeleket(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Write([]byte(`{"state":0,"result":{"uuid":"p1","status":"process"}}`))
+	})
+
+	_, err := client.CreatePayout(&heleket.PayoutRequest{
+		Amount: "5", Currency: "USDT", Network: "tron", OrderId: "o1", Address: "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6u",
+	})
+	require.ErrorIs(t, err, heleket.ErrInvalidAddress)
+	require.NotContains(t, err.Error(), "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6u")
+
+	_, err = client.CreatePayout(&heleket.PayoutRequest{
+		Amount: "5", Currency: "USDT", Network: "tron", OrderId: "o1", Address: "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t",
+		PayoutRequestOptions: &heleket.PayoutRequestOptions{Memo: "123"},
+	})
+	require.ErrorIs(t, err, heleket.ErrInvalidMemo)
+	require.Zero(t, calls.Load())
+
+	_, err = client.BlockedAddressRefund(&heleket.BlockedAddressRefundRequest{WalletUUID: "w1", Address: "not-an-address"})
+	require.ErrorIs(t, err, heleket.ErrInvalidAddress)
+	require.Zero(t, calls.Load())
+
+	unchecked := newStubHeleket(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Write([]byte(`{"state":0,"result":{"uuid":"p1","status":"process"}}`))
+	}, heleket.WithoutAddressValidation())
+	_, err = unchecked.CreatePayout(&heleket.PayoutRequest{
+		Amount: "5", Currency: "USDT", Network: "tron", OrderId: "o1", Address: "T1",
+	})
+	require.NoError(t, err)
+	require.Equal(t, int32(1), calls.Load())
+}
+
+func TestRefundAddressCheckedBeforeSending(t *testing.T) {
+	var calls atomic.Int32
+	client := newStubHeleket(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Write([]byte(`{"state":0,"result":[]}`))
+	})
+
+	_, err := client.Refund(&heleket.RefundRequest{PaymentUUID: "p1", Network: "tron", Address: "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"})
+	require.ErrorIs(t, err, heleket.ErrInvalidAddress)
+	require.Contains(t, err.Error(), "network tron")
+
+	// Without a network the address only has to be valid somewhere, and
+	// the payment is not looked up.
+	_, err = client.Refund(&heleket.RefundRequest{PaymentUUID: "p1", Address: "not-an-address"})
+	require.ErrorIs(t, err, heleket.ErrInvalidAddress)
+	require.Zero(t, calls.Load())
+
+	ok, err := client.Refund(&heleket.RefundRequest{PaymentUUID: "p1", Address: "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"})
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, int32(1), calls.Load())
+}
+
+func TestPayoutAddressCheckedBeforeLimits(t *testing.T) {
+	var calls atomic.Int32
+	client := newStubHeleket(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Write([]byte(`{"state":0,"result":[]}`))
+	}, heleket.WithLimitChecks(time.Minute))
+
+	_, err := client.CreatePayout(&heleket.PayoutRequest{
+		Amount: "5", Currency: "USDT", Network: "tron", OrderId: "o1", Address: "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6u",
+	})
+	require.ErrorIs(t, err, heleket.ErrInvalidAddress)
+	require.Zero(t, calls.Load())
+}
+
+func TestAddressChecksAfterRefresh(t *testing.T) {
+	fake := newFake(t,
+		heleketfake.WithPaymentServices([]*heleket.PaymentService{{Currency: "XRP", Network: "xrp", IsAvailable: true}}),
+		heleketfake.WithPayoutServices([]*heleket.PayoutService{{Currency: "XRP", Network: "xrp", IsAvailable: true}}),
+	)
+	registry := heleket.NewRegistry()
+	require.NoError(t, registry.Refresh(context.Background(), fake.Client()))
+
+	// A network without a validator does not make every address valid.
+	require.ErrorIs(t, registry.ValidateAddressAny("not-an-address"), heleket.ErrInvalidAddress)
+	require.NoError(t, registry.ValidateAddressAny("TXLAQ63Xg1NAzckPwKHvzw7CSEmLMEqcdj"))
+
+	// Nothing is known about memos on xrp, so they are left to the API.
+	require.NoError(t, registry.ValidateMemo("xrp", "12345"))
+	require.NoError(t, registry.ValidateMemo("xrp", ""))
+	require.NoError(t, registry.ValidateAddress("xrp", "rHb9CJAWyB4rj91VRWn96DkukG4bwdtyTh"))
+}
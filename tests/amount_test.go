@@ -0,0 +1,103 @@
+package tests
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/idanyas/heleket-go"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAmountJSONRoundTrip(t *testing.T) {
+	var v struct {
+		Amount     heleket.Amount `json:"amount"`
+		Commission heleket.Amount `json:"commission"`
+		Number     heleket.Amount `json:"number"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(`{"amount":"3.00000000","commission":null,"number":0.25}`), &v))
+
+	require.Equal(t, "3.00000000", v.Amount.String())
+	require.True(t, v.Commission.IsNull())
+	require.Equal(t, "0.25", v.Number.String())
+
+	out, err := json.Marshal(v)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"amount":"3.00000000","commission":null,"number":"0.25"}`, string(out))
+}
+
+func TestAmountArithmetic(t *testing.T) {
+	a := heleket.MustParseAmount("3.00000000")
+	b := heleket.MustParseAmount("0.06")
+
+	require.Equal(t, "2.94000000", a.Sub(b).String())
+	require.Equal(t, "3.06000000", a.Add(b).String())
+	require.Equal(t, "0.1800000000", a.Mul(b).String())
+	require.Equal(t, "50.00", a.Div(b, 2, heleket.RoundHalfUp).String())
+	require.Equal(t, "0.33333333", heleket.MustParseAmount("1").Div(heleket.MustParseAmount("3"), 8, heleket.RoundHalfEven).String())
+
+	require.True(t, a.Equal(heleket.MustParseAmount("3")))
+	require.True(t, b.LessThan(a))
+	require.True(t, a.GreaterThan(b))
+	require.Equal(t, 0, heleket.Amount{}.Cmp(heleket.MustParseAmount("0.000")))
+	require.Equal(t, "-0.06", b.Neg().String())
+	require.Equal(t, "1.50", heleket.NewAmount(150, 2).String())
+}
+
+func TestAmountRounding(t *testing.T) {
+	cases := []struct {
+		in   string
+		mode heleket.RoundingMode
+		want string
+	}{
+		{"2.345", heleket.RoundHalfUp, "2.35"},
+		{"2.345", heleket.RoundHalfEven, "2.34"},
+		{"2.355", heleket.RoundHalfEven, "2.36"},
+		{"2.349", heleket.RoundDown, "2.34"},
+		{"2.341", heleket.RoundUp, "2.35"},
+		{"-2.341", heleket.RoundFloor, "-2.35"},
+		{"-2.349", heleket.RoundCeiling, "-2.34"},
+		{"-2.345", heleket.RoundHalfUp, "-2.35"},
+		{"2.3", heleket.RoundDown, "2.30"},
+	}
+	for _, tc := range cases {
+		require.Equal(t, tc.want, heleket.MustParseAmount(tc.in).Round(2, tc.mode).String(), tc.in)
+	}
+}
+
+func TestAmountNegativeScale(t *testing.T) {
+	hundreds := heleket.NewAmount(5, -2)
+	require.Equal(t, "500", hundreds.String())
+	require.Equal(t, 500.0, hundreds.Float64())
+	require.Equal(t, "500/1", hundreds.Rat().String())
+	require.Equal(t, "250.00", hundreds.Div(heleket.MustParseAmount("2"), 2, heleket.RoundHalfUp).String())
+	require.True(t, hundreds.Equal(heleket.MustParseAmount("500")))
+
+	require.Equal(t, "1200", heleket.MustParseAmount("1234.5").Round(-2, heleket.RoundHalfUp).String())
+	require.Equal(t, "1300", heleket.MustParseAmount("1234.5").Round(-2, heleket.RoundUp).String())
+	require.Equal(t, "-1000", heleket.MustParseAmount("-1234.5").Round(-3, heleket.RoundHalfEven).String())
+	require.Equal(t, "1234.50", heleket.MustParseAmount("1234.5").Round(2, heleket.RoundHalfUp).String())
+}
+
+func TestAmountCurrencyPrecision(t *testing.T) {
+	amount := heleket.MustParseAmount("10.123456789")
+	require.Equal(t, "10.12", amount.StringFor("USD"))
+	require.Equal(t, "10.123457", amount.StringFor("USDT"))
+	require.Equal(t, "10.12345679", amount.StringFor("BTC"))
+}
+
+func TestAmountParseErrors(t *testing.T) {
+	for _, in := range []string{"abc", "1.2.3", "-", ".", "1e8", " 1"} {
+		_, err := heleket.ParseAmount(in)
+		require.Error(t, err, in)
+	}
+	a, err := heleket.ParseAmount("")
+	require.NoError(t, err)
+	require.True(t, a.IsNull())
+}
+
+func TestAmountAccessors(t *testing.T) {
+	payment := &heleket.Payment{MerchantAmount: "2.94000000", Commission: ""}
+	require.Equal(t, "2.94000000", payment.MerchantAmountValue().String())
+	require.True(t, payment.CommissionValue().IsNull())
+}
@@ -0,0 +1,150 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/idanyas/heleket-go"
+	"github.com/idanyas/heleket-go/heleketfake"
+
+	"github.com/stretchr/testify/require"
+)
+
+// windowHandler serves two payments per requested window, created one and
+// two minutes after its start, newest first. Earlier windows respond more
+// slowly so that they finish out of order.
+func windowHandler(inFlight, maxInFlight *atomic.Int32) http.HandlerFunc {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	return func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			m := maxInFlight.Load()
+			if n <= m || maxInFlight.CompareAndSwap(m, n) {
+				break
+			}
+		}
+
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		from, _ := time.Parse("2006-01-02 15:04:05", body["date_from"])
+		hours := int(from.Sub(start).Hours())
+		time.Sleep(time.Duration(10-hours) * 3 * time.Millisecond)
+
+		first, second := from.Add(time.Minute), from.Add(2*time.Minute)
+		fmt.Fprintf(w, `{"state":0,"result":{"items":[`+
+			`{"uuid":"h%d-b","created_at":%q},{"uuid":"h%d-a","created_at":%q}]}}`,
+			hours, second.Format(time.RFC3339), hours, first.Format(time.RFC3339))
+	}
+}
+
+func TestBackfillPaymentsInOrder(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+	client := newStubHeleket(t, windowHandler(&inFlight, &maxInFlight))
+
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	query := heleket.NewHistoryQuery(from, from.Add(6*time.Hour)).Window(time.Hour)
+
+	var uuids []string
+	var progress []heleket.BackfillProgress
+	for payment, err := range query.BackfillPayments(context.Background(), client,
+		heleket.WithWorkers(3),
+		heleket.WithProgress(func(p heleket.BackfillProgress) { progress = append(progress, p) })) {
+		require.NoError(t, err)
+		uuids = append(uuids, payment.UUID)
+	}
+
+	require.Equal(t, []string{
+		"h0-a", "h0-b", "h1-a", "h1-b", "h2-a", "h2-b",
+		"h3-a", "h3-b", "h4-a", "h4-b", "h5-a", "h5-b",
+	}, uuids)
+	require.LessOrEqual(t, maxInFlight.Load(), int32(3))
+	require.Greater(t, maxInFlight.Load(), int32(1))
+
+	require.Len(t, progress, 6)
+	for i, p := range progress {
+		require.Equal(t, i+1, p.WindowsDone)
+		require.Equal(t, 6, p.WindowsTotal)
+		require.Equal(t, 2, p.Items)
+		require.Equal(t, from.Add(time.Duration(i+1)*time.Hour), p.Checkpoint.Through)
+	}
+}
+
+func TestBackfillResumesFromCheckpoint(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+	client := newStubHeleket(t, windowHandler(&inFlight, &maxInFlight))
+
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	query := heleket.NewHistoryQuery(from, from.Add(6*time.Hour)).Window(time.Hour)
+
+	var checkpoint heleket.BackfillCheckpoint
+	count := 0
+	for _, err := range query.BackfillPayments(context.Background(), client,
+		heleket.WithProgress(func(p heleket.BackfillProgress) { checkpoint = p.Checkpoint })) {
+		require.NoError(t, err)
+		count++
+		if count == 5 {
+			break
+		}
+	}
+	require.Equal(t, from.Add(2*time.Hour), checkpoint.Through)
+
+	var uuids []string
+	for payment, err := range query.BackfillPayments(context.Background(), client, heleket.WithCheckpoint(checkpoint)) {
+		require.NoError(t, err)
+		uuids = append(uuids, payment.UUID)
+	}
+	require.Equal(t, []string{"h2-a", "h2-b", "h3-a", "h3-b", "h4-a", "h4-b", "h5-a", "h5-b"}, uuids)
+
+	done := heleket.BackfillCheckpoint{Through: from.Add(6 * time.Hour)}
+	for range query.BackfillPayments(context.Background(), client, heleket.WithCheckpoint(done)) {
+		t.Fatal("finished backfill yielded items")
+	}
+}
+
+func TestBackfillYieldsErrors(t *testing.T) {
+	var calls atomic.Int32
+	client := newStubHeleket(t, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 2 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"state":0,"result":{"items":[]}}`))
+	})
+
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	query := heleket.NewHistoryQuery(from, from.Add(4*time.Hour)).Window(time.Hour)
+	var last error
+	for payment, err := range query.BackfillPayments(context.Background(), client, heleket.WithWorkers(1)) {
+		require.Nil(t, payment)
+		last = err
+	}
+	require.ErrorIs(t, last, heleket.ErrUnauthorized)
+}
+
+func TestBackfillPayouts(t *testing.T) {
+	fake := newFake(t, heleketfake.WithPerPage(1))
+	client := fake.Client()
+	for i := range 3 {
+		_, err := client.CreatePayout(&heleket.PayoutRequest{
+			Amount: "1", Currency: "USDT", Network: "tron", OrderId: fmt.Sprintf("payout-%d", i), Address: "TXLAQ63Xg1NAzckPwKHvzw7CSEmLMEqcdj",
+		})
+		require.NoError(t, err)
+	}
+
+	query := heleket.NewHistoryQuery(time.Now().Add(-time.Hour), time.Now().Add(time.Hour)).Window(10 * time.Minute)
+	var created []time.Time
+	for payout, err := range query.BackfillPayouts(context.Background(), client) {
+		require.NoError(t, err)
+		created = append(created, payout.CreatedAt)
+	}
+	require.Len(t, created, 3)
+	for i := 1; i < len(created); i++ {
+		require.False(t, created[i].Before(created[i-1]))
+	}
+}
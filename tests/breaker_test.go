@@ -0,0 +1,115 @@
+package tests
+
+import (
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/idanyas/heleket-go"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCircuitBreakerOpensOnServerErrors(t *testing.T) {
+	var calls atomic.Int32
+	var healthy atomic.Bool
+	var transitions []string
+
+	client := newStubHeleket(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte(`{"state":0,"result":[]}`))
+	}, heleket.WithCircuitBreaker(heleket.CircuitBreakerSettings{
+		FailureThreshold: 2,
+		CoolDown:         30 * time.Millisecond,
+		OnStateChange: func(from, to heleket.CircuitState) {
+			transitions = append(transitions, from.String()+">"+to.String())
+		},
+	}))
+
+	for range 2 {
+		_, err := client.GetDiscountsList()
+		require.NotErrorIs(t, err, heleket.ErrCircuitOpen)
+	}
+	require.Equal(t, heleket.CircuitOpen, client.CircuitState())
+
+	_, err := client.GetDiscountsList()
+	require.ErrorIs(t, err, heleket.ErrCircuitOpen)
+	require.Equal(t, int32(2), calls.Load())
+
+	time.Sleep(40 * time.Millisecond)
+	healthy.Store(true)
+	_, err = client.GetDiscountsList()
+	require.NoError(t, err)
+	require.Equal(t, heleket.CircuitClosed, client.CircuitState())
+	require.Equal(t, []string{"closed>open", "open>half-open", "half-open>closed"}, transitions)
+}
+
+func TestCircuitBreakerIgnoresValidationErrors(t *testing.T) {
+	client := newStubHeleket(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		w.Write([]byte(`{"state":1,"errors":{"amount":["invalid"]}}`))
+	}, heleket.WithCircuitBreaker(heleket.CircuitBreakerSettings{FailureThreshold: 1}))
+
+	for range 3 {
+		_, err := client.CreateInvoice(&heleket.InvoiceRequest{Amount: "10", Currency: "USD", OrderId: "o"})
+		require.ErrorIs(t, err, heleket.ErrValidation)
+	}
+	require.Equal(t, heleket.CircuitClosed, client.CircuitState())
+}
+
+func TestCircuitBreakerReopensOnFailedTrial(t *testing.T) {
+	client := newStubHeleket(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}, heleket.WithCircuitBreaker(heleket.CircuitBreakerSettings{FailureThreshold: 1, CoolDown: 10 * time.Millisecond}))
+
+	_, err := client.GetBalance()
+	require.NotErrorIs(t, err, heleket.ErrCircuitOpen)
+
+	time.Sleep(20 * time.Millisecond)
+	require.Equal(t, heleket.CircuitHalfOpen, client.CircuitState())
+
+	_, err = client.GetBalance()
+	require.NotErrorIs(t, err, heleket.ErrCircuitOpen)
+	require.Equal(t, heleket.CircuitOpen, client.CircuitState())
+}
+
+func TestCircuitBreakerIgnoresCallsFromEarlierState(t *testing.T) {
+	var calls atomic.Int32
+	arrived := make(chan struct{})
+	client := newStubHeleket(t, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			close(arrived)
+			time.Sleep(60 * time.Millisecond)
+			w.Write([]byte(`{"state":0,"result":[]}`))
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}, heleket.WithCircuitBreaker(heleket.CircuitBreakerSettings{FailureThreshold: 1, CoolDown: 10 * time.Millisecond}))
+
+	// A slow call is admitted while closed and finishes while half-open.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		client.GetBalance()
+	}()
+	<-arrived
+
+	_, err := client.GetBalance()
+	require.NotErrorIs(t, err, heleket.ErrCircuitOpen)
+	require.Equal(t, heleket.CircuitOpen, client.CircuitState())
+
+	time.Sleep(20 * time.Millisecond)
+	require.Equal(t, heleket.CircuitHalfOpen, client.CircuitState())
+	<-done
+
+	// Its success neither closed the circuit nor used up the trial.
+	require.Equal(t, heleket.CircuitHalfOpen, client.CircuitState())
+	_, err = client.GetBalance()
+	require.NotErrorIs(t, err, heleket.ErrCircuitOpen)
+	require.Equal(t, heleket.CircuitOpen, client.CircuitState())
+}
@@ -0,0 +1,78 @@
+package tests
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/idanyas/heleket-go"
+	"github.com/idanyas/heleket-go/heleketcassette"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCassetteRecordAndReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "invoice.json")
+
+	server := httptest.NewServer(okHandler(`{"state":0,"result":{"uuid":"inv-1","order_id":"order-1","payment_status":"check"}}`))
+	defer server.Close()
+
+	recorder := heleketcassette.Record(path, heleketcassette.Options{
+		Secrets: []string{"merchant-secret", "payment-secret"},
+	})
+	live := heleket.New(recorder.Client(), "merchant-secret", "payment-secret", "payout-secret", heleket.WithBaseURL(server.URL+"/v1"))
+
+	invoiceReq := &heleket.InvoiceRequest{Amount: "10", Currency: "USD", OrderId: "order-1"}
+	recorded, err := live.CreateInvoice(invoiceReq)
+	require.NoError(t, err)
+	require.Nil(t, recorder.Unused())
+	require.NoError(t, recorder.Save())
+
+	fixture, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NotContains(t, string(fixture), "merchant-secret")
+	cassette := &heleketcassette.Cassette{}
+	require.NoError(t, json.Unmarshal(fixture, cassette))
+	require.Len(t, cassette.Interactions, 1)
+	require.Equal(t, heleketcassette.Redacted, cassette.Interactions[0].Request.Header.Get("sign"))
+	require.Equal(t, heleketcassette.Redacted, cassette.Interactions[0].Request.Header.Get("merchant"))
+
+	replayer, err := heleketcassette.Replay(path)
+	require.NoError(t, err)
+	offline := heleket.New(replayer.Client(), "merchant", "payment-key", "payout-key")
+
+	replayed, err := offline.CreateInvoice(invoiceReq)
+	require.NoError(t, err)
+	require.Equal(t, recorded, replayed)
+	require.Empty(t, replayer.Unused())
+
+	_, err = offline.CreateInvoice(invoiceReq)
+	require.ErrorIs(t, err, heleketcassette.ErrUnrecorded)
+
+	_, err = offline.GetBalance()
+	require.ErrorIs(t, err, heleketcassette.ErrUnrecorded)
+}
+
+func TestCassetteLeavesRequestUntouched(t *testing.T) {
+	server := httptest.NewServer(okHandler(`{"state":0,"result":[]}`))
+	defer server.Close()
+
+	recorder := heleketcassette.Record(filepath.Join(t.TempDir(), "raw.json"), heleketcassette.Options{})
+	req, err := http.NewRequest("POST", server.URL, strings.NewReader(`{"a":1}`))
+	require.NoError(t, err)
+	original := req.Body
+
+	res, err := recorder.RoundTrip(req)
+	require.NoError(t, err)
+	res.Body.Close()
+
+	require.Equal(t, original, req.Body)
+	body, err := io.ReadAll(req.Body)
+	require.NoError(t, err)
+	require.Equal(t, `{"a":1}`, string(body))
+}
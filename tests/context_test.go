@@ -0,0 +1,30 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/idanyas/heleket-go"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := TestHeleket.CreateInvoiceContext(ctx, &heleket.InvoiceRequest{Amount: "10", Currency: "USD", OrderId: "xxy"})
+	require.ErrorIs(t, err, context.Canceled)
+
+	_, err = TestHeleket.GetBalanceContext(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestContextDeadline(t *testing.T) {
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+
+	_, err := TestHeleket.GetPaymentHistoryContext(ctx, time.Now(), time.Now(), "")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
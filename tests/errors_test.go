@@ -0,0 +1,60 @@
+package tests
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/idanyas/heleket-go"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIErrorValidation(t *testing.T) {
+	client := newStubHeleket(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		w.Write([]byte(`{"state":1,"errors":{"amount":["The amount field is required."]}}`))
+	})
+
+	invoice, err := client.CreateInvoice(&heleket.InvoiceRequest{Amount: "10", Currency: "USD", OrderId: "xxy"})
+	require.Nil(t, invoice)
+	require.ErrorIs(t, err, heleket.ErrValidation)
+
+	var apiErr *heleket.APIError
+	require.True(t, errors.As(err, &apiErr))
+	require.Equal(t, http.StatusUnprocessableEntity, apiErr.StatusCode)
+	require.Equal(t, int8(1), apiErr.State)
+	require.Equal(t, []string{"The amount field is required."}, apiErr.Errors["amount"])
+}
+
+func TestAPIErrorStateOnSuccessStatus(t *testing.T) {
+	client := newStubHeleket(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"state":1,"message":"Payment not found"}`))
+	})
+
+	_, err := client.GetPaymentInfo(&heleket.PaymentInfoRequest{PaymentUUID: "missing"})
+	require.ErrorIs(t, err, heleket.ErrNotFound)
+	require.NotErrorIs(t, err, heleket.ErrValidation)
+}
+
+func TestAPIErrorClasses(t *testing.T) {
+	cases := []struct {
+		status int
+		body   string
+		want   error
+	}{
+		{http.StatusUnauthorized, `{"state":1,"message":"Invalid Sign"}`, heleket.ErrUnauthorized},
+		{http.StatusTooManyRequests, `Too Many Attempts.`, heleket.ErrRateLimited},
+		{http.StatusBadRequest, `{"state":1,"message":"Not enough funds"}`, heleket.ErrInsufficientFunds},
+	}
+
+	for _, tc := range cases {
+		client := newStubHeleket(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(tc.status)
+			w.Write([]byte(tc.body))
+		})
+
+		_, err := client.GetBalance()
+		require.ErrorIs(t, err, tc.want)
+	}
+}
@@ -0,0 +1,206 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"iter"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/idanyas/heleket-go"
+	"github.com/idanyas/heleket-go/heleketexport"
+
+	"github.com/stretchr/testify/require"
+)
+
+func seqOf[T any](items []T, err error) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		for _, item := range items {
+			if !yield(item, nil) {
+				return
+			}
+		}
+		if err != nil {
+			yield(zero, err)
+		}
+	}
+}
+
+var exportCreated = time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func exportPayments() []*heleket.Payment {
+	return []*heleket.Payment{
+		{
+			UUID: "p1", OrderId: "order-1", CreatedAt: exportCreated, UpdatedAt: exportCreated.Add(time.Minute),
+			PaymentStatus: heleket.StatusPaid, IsFinal: true,
+			Amount: "10.5", Currency: "USD", PayerCurrency: "BTC", PayerAmount: "0.00012", PaymentAmount: "0.00012",
+			MerchantAmount: "0.0001176", Commission: "0.0000024", Network: "btc", TxId: "abc",
+			Convert: &heleket.PaymentConvert{ToCurrency: "USDT", Amount: "10.2", Commission: "0.1", Rate: "85000.5"},
+		},
+		{
+			UUID: "p2", OrderId: "order-2", CreatedAt: exportCreated.Add(time.Hour),
+			PaymentStatus: heleket.StatusCheck, Amount: "3", Currency: "USDT",
+		},
+	}
+}
+
+func TestPaymentsCSV(t *testing.T) {
+	var buf bytes.Buffer
+	err := heleketexport.PaymentsCSV(&buf, seqOf(exportPayments(), nil),
+		heleketexport.WithColumns("uuid", "created_at", "status", "amount", "payer_amount", "commission", "convert_to_currency", "convert_amount", "convert_commission"))
+	require.NoError(t, err)
+	require.Equal(t, ""+
+		"uuid,created_at,status,amount,payer_amount,commission,convert_to_currency,convert_amount,convert_commission\n"+
+		"p1,2025-03-01T12:00:00Z,paid,10.50,0.00012000,0.00000240,USDT,10.200000,0.100000\n"+
+		"p2,2025-03-01T13:00:00Z,check,3.000000,,,,,\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, heleketexport.PaymentsCSV(&buf, seqOf(exportPayments(), nil), heleketexport.WithoutHeader(), heleketexport.WithComma(';')))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	require.Len(t, strings.Split(lines[0], ";"), len(heleketexport.PaymentColumns))
+
+	err = heleketexport.PaymentsCSV(io.Discard, seqOf(exportPayments(), nil), heleketexport.WithColumns("uuid", "colour"))
+	require.ErrorIs(t, err, heleketexport.ErrUnknownColumn)
+
+	iterErr := errors.New("history failed")
+	buf.Reset()
+	err = heleketexport.PaymentsCSV(&buf, seqOf(exportPayments()[:1], iterErr), heleketexport.WithColumns("uuid"))
+	require.ErrorIs(t, err, iterErr)
+	require.Equal(t, "uuid\np1\n", buf.String())
+}
+
+func TestPaymentsJSONL(t *testing.T) {
+	var buf bytes.Buffer
+	err := heleketexport.PaymentsJSONL(&buf, seqOf(exportPayments(), nil),
+		heleketexport.WithColumns("uuid", "is_final", "amount", "convert_amount", "updated_at"))
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	require.Equal(t, `{"uuid":"p1","is_final":true,"amount":"10.50","convert_amount":"10.200000","updated_at":"2025-03-01T12:01:00Z"}`, lines[0])
+
+	var second map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &second))
+	require.Equal(t, map[string]any{"uuid": "p2", "is_final": false, "amount": "3.000000", "convert_amount": nil, "updated_at": nil}, second)
+}
+
+func TestExportKeepsReportedDigits(t *testing.T) {
+	payments := []*heleket.Payment{{
+		UUID: "p1", PaymentStatus: heleket.StatusPaid, UpdatedAt: exportCreated,
+		Amount: "9.92098786", Currency: "USDT", PayerCurrency: "USDT", MerchantAmount: "9.72256810", Commission: "0.19841976",
+	}}
+
+	var buf bytes.Buffer
+	require.NoError(t, heleketexport.PaymentsCSV(&buf, seqOf(payments, nil), heleketexport.WithColumns("amount", "merchant_amount", "commission")))
+	require.Equal(t, "amount,merchant_amount,commission\n9.92098786,9.72256810,0.19841976\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, heleketexport.PaymentsOFX(&buf, seqOf(payments, nil), heleketexport.OFXStatement{Currency: "USDT", AccountID: "merchant-1"}))
+	require.Contains(t, buf.String(), "<TRNAMT>9.72256810</TRNAMT>")
+	require.Contains(t, buf.String(), "<MEMO>commission 0.19841976 USDT</MEMO>")
+}
+
+func TestPayoutsCSVAndJSONL(t *testing.T) {
+	payouts := []*heleket.Payout{{
+		UUID: "o1", CreatedAt: exportCreated, Status: heleket.StatusPaid, IsFinal: true,
+		Amount: "5", Currency: "USDT", PayerCurrency: "USDT", PayerAmount: "6.1", Balance: "93.9", Network: "tron", Address: "TXLAQ63Xg1NAzckPwKHvzw7CSEmLMEqcdj",
+	}}
+
+	var buf bytes.Buffer
+	require.NoError(t, heleketexport.PayoutsCSV(&buf, seqOf(payouts, nil), heleketexport.WithColumns("uuid", "amount", "payer_amount", "balance")))
+	require.Equal(t, "uuid,amount,payer_amount,balance\no1,5.000000,6.100000,93.900000\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, heleketexport.PayoutsJSONL(&buf, seqOf(payouts, nil)))
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	require.Len(t, record, len(heleketexport.PayoutColumns))
+	require.Equal(t, "paid", record["status"])
+}
+
+func TestPaymentsOFX(t *testing.T) {
+	stmt := heleketexport.OFXStatement{
+		Currency: "USDT", AccountID: "merchant-1", IntuitBID: "3000",
+		From: exportCreated.Add(-time.Hour), To: exportCreated.Add(2 * time.Hour),
+	}
+	var buf bytes.Buffer
+	require.NoError(t, heleketexport.PaymentsOFX(&buf, seqOf(exportPayments(), nil), stmt))
+	out := buf.String()
+
+	require.Contains(t, out, "<CURDEF>USDT</CURDEF>")
+	require.Contains(t, out, "<INTU.BID>3000</INTU.BID>")
+	require.Contains(t, out, "<DTSTART>20250301110000.000[0:GMT]</DTSTART>")
+	require.Contains(t, out, "<TRNTYPE>CREDIT</TRNTYPE>\n<DTPOSTED>20250301120100.000[0:GMT]</DTPOSTED>\n<TRNAMT>10.200000</TRNAMT>\n<FITID>p1</FITID>")
+	require.Contains(t, out, "<MEMO>commission 0.00000240 BTC; converted to 10.200000 USDT at 85000.5; conversion commission 0.100000 USDT; txid abc</MEMO>")
+	require.Contains(t, out, "<LEDGERBAL>\n<BALAMT>10.200000</BALAMT>\n<DTASOF>20250301140000.000[0:GMT]</DTASOF>\n</LEDGERBAL>\n</STMTRS>")
+	// The unpaid invoice is not part of the statement.
+	require.Equal(t, 1, strings.Count(out, "<STMTTRN>"))
+	requireWellFormed(t, out)
+
+	stmt.Balance = heleket.MustParseAmount("1250.5")
+	buf.Reset()
+	require.NoError(t, heleketexport.PaymentsOFX(&buf, seqOf(exportPayments(), nil), stmt))
+	require.Contains(t, buf.String(), "<BALAMT>1250.500000</BALAMT>")
+
+	err := heleketexport.PaymentsOFX(io.Discard, seqOf(exportPayments(), nil), heleketexport.OFXStatement{Currency: "USDT"})
+	require.Error(t, err)
+}
+
+func TestPaymentsOFXRefunds(t *testing.T) {
+	refunded := exportCreated.Add(48 * time.Hour)
+	payments := []*heleket.Payment{
+		{UUID: "r1", OrderId: "order-1", CreatedAt: exportCreated, UpdatedAt: refunded, PaymentStatus: heleket.StatusRefundPaid,
+			Amount: "10", Currency: "USDT", PayerCurrency: "USDT", MerchantAmount: "9.8"},
+		{UUID: "r2", OrderId: "order-2", CreatedAt: exportCreated, UpdatedAt: refunded, PaymentStatus: heleket.StatusRefundProcess,
+			Amount: "5", Currency: "USDT", PayerCurrency: "USDT", MerchantAmount: "4.9"},
+	}
+	var buf bytes.Buffer
+	require.NoError(t, heleketexport.PaymentsOFX(&buf, seqOf(payments, nil), heleketexport.OFXStatement{Currency: "USDT", AccountID: "merchant-1"}))
+	out := buf.String()
+
+	require.Equal(t, 3, strings.Count(out, "<STMTTRN>"))
+	require.Contains(t, out, "<TRNTYPE>CREDIT</TRNTYPE>\n<DTPOSTED>20250301120000.000[0:GMT]</DTPOSTED>\n<TRNAMT>9.800000</TRNAMT>\n<FITID>r1</FITID>")
+	require.Contains(t, out, "<TRNTYPE>DEBIT</TRNTYPE>\n<DTPOSTED>20250303120000.000[0:GMT]</DTPOSTED>\n<TRNAMT>-9.800000</TRNAMT>\n<FITID>r1-refund</FITID>")
+	// The refund still in progress keeps its credit only.
+	require.Contains(t, out, "<TRNAMT>4.900000</TRNAMT>\n<FITID>r2</FITID>")
+	require.NotContains(t, out, "r2-refund")
+	require.Contains(t, out, "<BALAMT>4.900000</BALAMT>")
+	requireWellFormed(t, out)
+}
+
+func TestPayoutsOFX(t *testing.T) {
+	payouts := []*heleket.Payout{
+		{UUID: "o1", UpdatedAt: exportCreated, Status: heleket.StatusPaid, Amount: "5", Currency: "USDT", PayerCurrency: "USDT", PayerAmount: "6.1", Network: "tron", Address: "T<&>"},
+		{UUID: "o2", UpdatedAt: exportCreated, Status: heleket.StatusFail, Amount: "5", Currency: "USDT"},
+		{UUID: "o3", UpdatedAt: exportCreated, Status: heleket.StatusPaid, Amount: "0.001", Currency: "BTC", PayerCurrency: "BTC", PayerAmount: "0.0011"},
+	}
+	var buf bytes.Buffer
+	require.NoError(t, heleketexport.PayoutsOFX(&buf, seqOf(payouts, nil), heleketexport.OFXStatement{Currency: "usdt", AccountID: "merchant-1"}))
+	out := buf.String()
+
+	require.Equal(t, 1, strings.Count(out, "<STMTTRN>"))
+	require.Contains(t, out, "<TRNTYPE>DEBIT</TRNTYPE>")
+	require.Contains(t, out, "<TRNAMT>-6.100000</TRNAMT>")
+	require.Contains(t, out, "</BANKTRANLIST>\n<LEDGERBAL>\n<BALAMT>-6.100000</BALAMT>\n<DTASOF>")
+	require.Contains(t, out, "<NAME>T&lt;&amp;&gt;</NAME>")
+	require.NotContains(t, out, "INTU.BID")
+	requireWellFormed(t, out)
+}
+
+func requireWellFormed(t *testing.T, doc string) {
+	t.Helper()
+	dec := xml.NewDecoder(strings.NewReader(doc))
+	for {
+		_, err := dec.Token()
+		if err == io.EOF {
+			return
+		}
+		require.NoError(t, err)
+	}
+}
@@ -0,0 +1,227 @@
+package tests
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/idanyas/heleket-go"
+	"github.com/idanyas/heleket-go/heleketfake"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newFake(t *testing.T, opts ...heleketfake.Option) *heleketfake.Server {
+	fake := heleketfake.New(opts...)
+	t.Cleanup(fake.Close)
+	return fake
+}
+
+func TestFakeRejectsBadSign(t *testing.T) {
+	fake := newFake(t)
+	client := heleket.New(nil, heleketfake.DefaultMerchant, "wrong-key", heleketfake.DefaultPayoutKey, heleket.WithBaseURL(fake.URL()))
+
+	_, err := client.GetBalance()
+	require.ErrorIs(t, err, heleket.ErrUnauthorized)
+
+	_, err = client.GetPayoutServicesList()
+	require.NoError(t, err)
+}
+
+func TestFakeInvoiceLifecycle(t *testing.T) {
+	fake := newFake(t)
+	client := fake.Client()
+
+	invoice, err := client.CreateInvoice(&heleket.InvoiceRequest{
+		Amount:                "15",
+		Currency:              "USD",
+		OrderId:               "order-1",
+		InvoiceRequestOptions: &heleket.InvoiceRequestOptions{ToCurrency: "USDT", Network: "tron"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, heleket.StatusCheck, invoice.PaymentStatus)
+	require.Equal(t, "15.00000000", invoice.PayerAmount)
+	require.NotEmpty(t, invoice.Address)
+
+	again, err := client.CreateInvoice(&heleket.InvoiceRequest{Amount: "15", Currency: "USD", OrderId: "order-1"})
+	require.NoError(t, err)
+	require.Equal(t, invoice.UUID, again.UUID)
+
+	_, err = client.Refund(&heleket.RefundRequest{PaymentUUID: invoice.UUID, Address: "TXLAQ63Xg1NAzckPwKHvzw7CSEmLMEqcdj"})
+	require.Error(t, err)
+
+	require.True(t, fake.UpdatePayment(invoice.UUID, func(p *heleket.Payment) {
+		p.PaymentStatus, p.Status, p.IsFinal = heleket.StatusPaid, heleket.StatusPaid, true
+	}))
+
+	ok, err := client.Refund(&heleket.RefundRequest{OrderId: "order-1", Address: "TXLAQ63Xg1NAzckPwKHvzw7CSEmLMEqcdj"})
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	info, err := client.GetPaymentInfo(&heleket.PaymentInfoRequest{OrderId: "order-1"})
+	require.NoError(t, err)
+	require.Equal(t, heleket.StatusRefundProcess, info.PaymentStatus)
+
+	_, err = client.GetPaymentInfo(&heleket.PaymentInfoRequest{OrderId: "missing"})
+	require.ErrorIs(t, err, heleket.ErrNotFound)
+}
+
+func TestFakeHistoryPagination(t *testing.T) {
+	fake := newFake(t, heleketfake.WithPerPage(2))
+	client := fake.Client()
+
+	for i := range 5 {
+		_, err := client.CreateInvoice(&heleket.InvoiceRequest{Amount: "1", Currency: "USDT", OrderId: fmt.Sprintf("order-%d", i)})
+		require.NoError(t, err)
+	}
+
+	from, to := time.Now().UTC().Add(-time.Hour), time.Now().UTC().Add(time.Hour)
+	seen := map[string]bool{}
+	cursor := ""
+	for {
+		page, err := client.GetPaymentHistory(from, to, cursor)
+		require.NoError(t, err)
+		for _, p := range page.Payments {
+			seen[p.OrderId] = true
+		}
+		if page.Paginate.NextCursor == "" {
+			break
+		}
+		cursor = page.Paginate.NextCursor
+	}
+	require.Len(t, seen, 5)
+}
+
+func TestFakePayoutsAndBalance(t *testing.T) {
+	fake := newFake(t, heleketfake.WithBalance("USDT", "50"))
+	client := fake.Client()
+
+	payout, err := client.CreatePayout(&heleket.PayoutRequest{
+		Amount: "20", Currency: "USDT", Network: "tron", OrderId: "payout-1", Address: "TXLAQ63Xg1NAzckPwKHvzw7CSEmLMEqcdj",
+	})
+	require.NoError(t, err)
+	require.Equal(t, heleket.StatusProcess, payout.Status)
+	require.Equal(t, "30.00000000", payout.Balance)
+
+	_, err = client.CreatePayout(&heleket.PayoutRequest{
+		Amount: "40", Currency: "USDT", Network: "tron", OrderId: "payout-2", Address: "TXLAQ63Xg1NAzckPwKHvzw7CSEmLMEqcdj",
+	})
+	require.ErrorIs(t, err, heleket.ErrInsufficientFunds)
+
+	info, err := client.GetPayoutInfo(&heleket.PayoutInfoRequest{OrderId: "payout-1"})
+	require.NoError(t, err)
+	require.Equal(t, payout.UUID, info.UUID)
+
+	balance, err := client.GetBalance()
+	require.NoError(t, err)
+	for _, b := range balance.Merchant {
+		if b.CurrencyCode == "USDT" {
+			require.Equal(t, "30.00000000", b.Balance)
+		}
+	}
+}
+
+func TestFakeUpdatePayoutBumpsUpdatedAt(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	fake := newFake(t, heleketfake.WithClock(func() time.Time { return now }))
+	client := fake.Client()
+
+	payout, err := client.CreatePayout(&heleket.PayoutRequest{
+		Amount: "1", Currency: "USDT", Network: "tron", OrderId: "payout-1", Address: "TXLAQ63Xg1NAzckPwKHvzw7CSEmLMEqcdj",
+	})
+	require.NoError(t, err)
+
+	now = now.Add(time.Hour)
+	require.True(t, fake.UpdatePayout(payout.UUID, func(p *heleket.Payout) { p.Status = heleket.StatusPaid }))
+
+	info, err := client.GetPayoutInfo(&heleket.PayoutInfoRequest{OrderId: "payout-1"})
+	require.NoError(t, err)
+	require.True(t, now.Equal(info.UpdatedAt), info.UpdatedAt)
+}
+
+func TestFakeStaticWallets(t *testing.T) {
+	client := newFake(t).Client()
+
+	wallet, err := client.CreateStaticWallet(&heleket.StaticWalletRequest{Currency: "USDT", Network: "tron", OrderId: "wallet-1"})
+	require.NoError(t, err)
+	require.NotEmpty(t, wallet.Address)
+
+	qr, err := client.GenerateStaticWalletQRCode(wallet.UUID)
+	require.NoError(t, err)
+	require.NotEmpty(t, qr)
+
+	_, err = client.BlockedAddressRefund(&heleket.BlockedAddressRefundRequest{WalletUUID: wallet.UUID, Address: "TXLAQ63Xg1NAzckPwKHvzw7CSEmLMEqcdj"})
+	require.Error(t, err)
+
+	blocked, err := client.BlockAddress(&heleket.BlockAddressRequest{OrderId: "wallet-1"})
+	require.NoError(t, err)
+	require.Equal(t, "blocked", blocked.Status)
+
+	refund, err := client.BlockedAddressRefund(&heleket.BlockedAddressRefundRequest{WalletUUID: wallet.UUID, Address: "TXLAQ63Xg1NAzckPwKHvzw7CSEmLMEqcdj"})
+	require.NoError(t, err)
+	require.Equal(t, "0.00000000", refund.Amount)
+}
+
+func TestFakeAccountEndpoints(t *testing.T) {
+	client := newFake(t).Client()
+
+	discount, err := client.SetDiscount(&heleket.SetDiscountRequest{Currency: "USDT", Network: "tron", DiscountPercent: 5})
+	require.NoError(t, err)
+	require.Equal(t, int8(5), discount.Discount)
+
+	discounts, err := client.GetDiscountsList()
+	require.NoError(t, err)
+	require.Contains(t, discounts, &heleket.Discount{Currency: "USDT", Network: "tron", Discount: 5})
+
+	rates, err := client.GetExchangeRates("BTC")
+	require.NoError(t, err)
+	require.Contains(t, rates, &heleket.ExchangeRate{From: "BTC", To: "USDT", Course: "60000.00000000"})
+
+	services, err := client.GetPaymentServicesList()
+	require.NoError(t, err)
+	require.NotEmpty(t, services)
+}
+
+func TestFakeConcurrentUpdatesAndReads(t *testing.T) {
+	fake := newFake(t)
+	client := fake.Client()
+	invoice, err := client.CreateInvoice(&heleket.InvoiceRequest{Amount: "10", Currency: "USDT", OrderId: "order-1"})
+	require.NoError(t, err)
+	payout, err := client.CreatePayout(&heleket.PayoutRequest{
+		Amount: "1", Currency: "USDT", Network: "tron", OrderId: "payout-1", Address: "TXLAQ63Xg1NAzckPwKHvzw7CSEmLMEqcdj",
+	})
+	require.NoError(t, err)
+
+	// Responses are encoded while the fake keeps updating the records.
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			fake.UpdatePayment(invoice.UUID, func(p *heleket.Payment) { p.Comments = fmt.Sprint(i) })
+			fake.UpdatePayout(payout.UUID, func(p *heleket.Payout) { p.TxId = fmt.Sprint(i) })
+			time.Sleep(100 * time.Microsecond)
+		}
+	}()
+
+	from, to := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+	for range 10 {
+		_, err := client.GetPaymentInfo(&heleket.PaymentInfoRequest{PaymentUUID: invoice.UUID})
+		require.NoError(t, err)
+		_, err = client.GetPaymentHistory(from, to, "")
+		require.NoError(t, err)
+		_, err = client.GetPayoutInfo(&heleket.PayoutInfoRequest{PayoutUUID: payout.UUID})
+		require.NoError(t, err)
+		_, err = client.GetPayoutHistory(from, to, "")
+		require.NoError(t, err)
+	}
+	close(stop)
+	wg.Wait()
+}
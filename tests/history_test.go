@@ -0,0 +1,166 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/idanyas/heleket-go"
+	"github.com/idanyas/heleket-go/heleketfake"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistoryDatesUseAPILocation(t *testing.T) {
+	var mu sync.Mutex
+	var bodies []map[string]string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		bodies = append(bodies, body)
+		mu.Unlock()
+		w.Write([]byte(`{"state":0,"result":{"items":[]}}`))
+	}
+
+	tokyo := time.FixedZone("JST", 9*60*60)
+	from := time.Date(2025, 3, 1, 9, 0, 0, 0, tokyo)
+	to := from.Add(time.Hour)
+
+	client := newStubHeleket(t, handler)
+	_, err := client.GetPaymentHistory(from, to, "")
+	require.NoError(t, err)
+
+	moscow := time.FixedZone("MSK", 3*60*60)
+	client = newStubHeleket(t, handler, heleket.WithAPILocation(moscow))
+	_, err = client.GetPayoutHistory(from, to, "")
+	require.NoError(t, err)
+
+	require.Len(t, bodies, 2)
+	require.Equal(t, "2025-03-01 00:00:00", bodies[0]["date_from"])
+	require.Equal(t, "2025-03-01 01:00:00", bodies[0]["date_to"])
+	require.Equal(t, "2025-03-01 03:00:00", bodies[1]["date_from"])
+}
+
+func TestHistoryQueryWindows(t *testing.T) {
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	windows := heleket.NewHistoryQuery(from, from.AddDate(0, 0, 70)).Windows()
+	require.Len(t, windows, 3)
+	require.Equal(t, from, windows[0].From)
+	require.Equal(t, windows[0].To, windows[1].From)
+	require.Equal(t, from.AddDate(0, 0, 70), windows[2].To)
+
+	windows = heleket.NewHistoryQuery(from, from.Add(10*time.Hour)).Window(4 * time.Hour).Windows()
+	require.Len(t, windows, 3)
+	require.Equal(t, 2*time.Hour, windows[2].To.Sub(windows[2].From))
+
+	windows = heleket.NewHistoryQuery(from, from).Windows()
+	require.Equal(t, []heleket.HistoryWindow{{From: from, To: from}}, windows)
+}
+
+func TestHistoryQuerySplitsAndDeduplicates(t *testing.T) {
+	var mu sync.Mutex
+	var ranges []string
+	client := newStubHeleket(t, func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		n := len(ranges)
+		ranges = append(ranges, body["date_from"]+" - "+body["date_to"])
+		mu.Unlock()
+		// Every window returns its own payment and the ones on the
+		// boundaries it shares with its neighbours.
+		fmt.Fprintf(w, `{"state":0,"result":{"items":[{"uuid":"edge-%d"},{"uuid":"w%d"},{"uuid":"edge-%d"}]}}`, n-1, n, n)
+	})
+
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	var uuids []string
+	for payment, err := range heleket.NewHistoryQuery(from, from.Add(3*time.Hour)).Window(time.Hour).Payments(context.Background(), client) {
+		require.NoError(t, err)
+		uuids = append(uuids, payment.UUID)
+	}
+	require.Equal(t, []string{"edge--1", "w0", "edge-0", "w1", "edge-1", "w2", "edge-2"}, uuids)
+	require.Equal(t, []string{
+		"2025-01-01 00:00:00 - 2025-01-01 01:00:00",
+		"2025-01-01 01:00:00 - 2025-01-01 02:00:00",
+		"2025-01-01 02:00:00 - 2025-01-01 03:00:00",
+	}, ranges)
+}
+
+func TestHistoryQueryFilters(t *testing.T) {
+	fake := newFake(t)
+	client := fake.Client()
+	invoices := []*heleket.InvoiceRequest{
+		{Amount: "5", Currency: "USDT", OrderId: "shop-1", InvoiceRequestOptions: &heleket.InvoiceRequestOptions{Network: "tron"}},
+		{Amount: "50", Currency: "USDT", OrderId: "shop-2", InvoiceRequestOptions: &heleket.InvoiceRequestOptions{Network: "tron"}},
+		{Amount: "50", Currency: "USDT", OrderId: "other-3", InvoiceRequestOptions: &heleket.InvoiceRequestOptions{Network: "tron"}},
+		{Amount: "500", Currency: "BTC", OrderId: "shop-4", InvoiceRequestOptions: &heleket.InvoiceRequestOptions{Network: "btc"}},
+	}
+	for _, invoice := range invoices {
+		_, err := client.CreateInvoice(invoice)
+		require.NoError(t, err)
+	}
+
+	from, to := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+	orders := func(q *heleket.HistoryQuery) []string {
+		var orders []string
+		for payment, err := range q.Payments(context.Background(), client) {
+			require.NoError(t, err)
+			orders = append(orders, payment.OrderId)
+		}
+		return orders
+	}
+
+	require.Len(t, orders(heleket.NewHistoryQuery(from, to)), 4)
+	require.ElementsMatch(t, []string{"shop-1", "shop-2", "shop-4"}, orders(heleket.NewHistoryQuery(from, to).OrderIdPrefix("shop-")))
+	require.ElementsMatch(t, []string{"shop-1", "shop-2", "other-3"}, orders(heleket.NewHistoryQuery(from, to).Currency("usdt").Network("TRON")))
+	require.ElementsMatch(t, []string{"shop-2", "other-3"},
+		orders(heleket.NewHistoryQuery(from, to).AmountRange(heleket.MustParseAmount("10"), heleket.MustParseAmount("100"))))
+	require.ElementsMatch(t, []string{"shop-2", "other-3", "shop-4"}, orders(heleket.NewHistoryQuery(from, to).AmountRange(heleket.MustParseAmount("50"), heleket.Amount{})))
+	require.Empty(t, orders(heleket.NewHistoryQuery(from, to).Status(heleket.StatusPaid)))
+	require.Len(t, orders(heleket.NewHistoryQuery(from, to).Status(heleket.StatusCheck).Prefetch(1)), 4)
+}
+
+func TestHistoryQueryPayouts(t *testing.T) {
+	fake := newFake(t, heleketfake.WithPerPage(1))
+	client := fake.Client()
+	for i, network := range []string{"tron", "tron", "eth"} {
+		address := "TXLAQ63Xg1NAzckPwKHvzw7CSEmLMEqcdj"
+		if network == "eth" {
+			address = "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"
+		}
+		_, err := client.CreatePayout(&heleket.PayoutRequest{
+			Amount: "20", Currency: "USDT", Network: network, OrderId: fmt.Sprintf("payout-%d", i), Address: address,
+		})
+		require.NoError(t, err)
+	}
+
+	count := 0
+	for payout, err := range heleket.NewHistoryQuery(time.Now().Add(-time.Hour), time.Now().Add(time.Hour)).Network("tron").Payouts(context.Background(), client) {
+		require.NoError(t, err)
+		require.Equal(t, "tron", payout.Network)
+		count++
+	}
+	require.Equal(t, 2, count)
+
+	// Payouts carry no order_id, so an order id prefix cannot be applied.
+	query := heleket.NewHistoryQuery(time.Now().Add(-time.Hour), time.Now().Add(time.Hour)).OrderIdPrefix("payout-")
+	for _, payouts := range []iter.Seq2[*heleket.Payout, error]{
+		query.Payouts(context.Background(), client),
+		query.BackfillPayouts(context.Background(), client),
+	} {
+		var errs []error
+		for payout, err := range payouts {
+			require.Nil(t, payout)
+			errs = append(errs, err)
+		}
+		require.Len(t, errs, 1)
+		require.ErrorContains(t, errs[0], "OrderIdPrefix")
+	}
+}
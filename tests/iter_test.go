@@ -0,0 +1,134 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/idanyas/heleket-go"
+	"github.com/idanyas/heleket-go/heleketfake"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaymentsSeqFollowsCursors(t *testing.T) {
+	fake := newFake(t, heleketfake.WithPerPage(2))
+	client := fake.Client()
+	for i := range 5 {
+		_, err := client.CreateInvoice(&heleket.InvoiceRequest{Amount: "1", Currency: "USDT", OrderId: fmt.Sprintf("order-%d", i)})
+		require.NoError(t, err)
+	}
+
+	from, to := time.Now().UTC().Add(-time.Hour), time.Now().UTC().Add(time.Hour)
+	for _, opts := range [][]heleket.SeqOption{nil, {heleket.WithPrefetch(2)}} {
+		var orders []string
+		for payment, err := range client.PaymentsSeq(context.Background(), from, to, opts...) {
+			require.NoError(t, err)
+			orders = append(orders, payment.OrderId)
+		}
+		require.Len(t, orders, 5)
+		require.ElementsMatch(t, []string{"order-0", "order-1", "order-2", "order-3", "order-4"}, orders)
+	}
+}
+
+func TestPayoutsSeq(t *testing.T) {
+	fake := newFake(t, heleketfake.WithPerPage(1))
+	client := fake.Client()
+	for i := range 3 {
+		_, err := client.CreatePayout(&heleket.PayoutRequest{
+			Amount: "1", Currency: "USDT", Network: "tron", OrderId: fmt.Sprintf("payout-%d", i), Address: "TXLAQ63Xg1NAzckPwKHvzw7CSEmLMEqcdj",
+		})
+		require.NoError(t, err)
+	}
+
+	from, to := time.Now().UTC().Add(-time.Hour), time.Now().UTC().Add(time.Hour)
+	count := 0
+	for _, err := range client.PayoutsSeq(context.Background(), from, to) {
+		require.NoError(t, err)
+		count++
+	}
+	require.Equal(t, 3, count)
+}
+
+// pagedHandler serves an endless history where every page links to the next.
+func pagedHandler(calls *atomic.Int32) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		n := calls.Add(1)
+		fmt.Fprintf(w, `{"state":0,"result":{"items":[{"uuid":"p%d-a"},{"uuid":"p%d-b"}],"paginate":{"nextCursor":"c%d"}}}`, n, n, n)
+	}
+}
+
+func TestPaymentsSeqBreakStopsFetching(t *testing.T) {
+	for _, prefetch := range []int{0, 3} {
+		var calls atomic.Int32
+		client := newStubHeleket(t, pagedHandler(&calls))
+
+		seen := 0
+		for _, err := range client.PaymentsSeq(context.Background(), time.Now(), time.Now(), heleket.WithPrefetch(prefetch)) {
+			require.NoError(t, err)
+			seen++
+			if seen == 3 {
+				break
+			}
+		}
+		require.Equal(t, 3, seen)
+
+		// A request canceled by the break may still reach the server.
+		time.Sleep(20 * time.Millisecond)
+		settled := calls.Load()
+		require.LessOrEqual(t, settled, int32(3+prefetch))
+		time.Sleep(20 * time.Millisecond)
+		require.Equal(t, settled, calls.Load())
+	}
+}
+
+func TestPaymentsSeqYieldsErrors(t *testing.T) {
+	var calls atomic.Int32
+	client := newStubHeleket(t, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Write([]byte(`{"state":0,"result":{"items":[{"uuid":"a"}],"paginate":{"nextCursor":"same"}}}`))
+			return
+		}
+		w.Write([]byte(`{"state":0,"result":{"items":[{"uuid":"b"}],"paginate":{"nextCursor":"same"}}}`))
+	})
+
+	var uuids []string
+	var last error
+	for payment, err := range client.PaymentsSeq(context.Background(), time.Now(), time.Now()) {
+		if err != nil {
+			last = err
+			continue
+		}
+		uuids = append(uuids, payment.UUID)
+	}
+	require.Equal(t, []string{"a", "b"}, uuids)
+	require.ErrorIs(t, last, heleket.ErrCursorLoop)
+
+	client = newStubHeleket(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+	for payment, err := range client.PaymentsSeq(context.Background(), time.Now(), time.Now(), heleket.WithPrefetch(1)) {
+		require.Nil(t, payment)
+		require.ErrorIs(t, err, heleket.ErrUnauthorized)
+	}
+}
+
+func TestPaymentsSeqReportsCancellation(t *testing.T) {
+	var calls atomic.Int32
+	client := newStubHeleket(t, pagedHandler(&calls))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var last error
+	for _, err := range client.PaymentsSeq(ctx, time.Now(), time.Now(), heleket.WithPrefetch(1)) {
+		if err != nil {
+			last = err
+			break
+		}
+		cancel()
+	}
+	require.ErrorIs(t, last, context.Canceled)
+}
@@ -0,0 +1,124 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/idanyas/heleket-go"
+	"github.com/idanyas/heleket-go/heleketfake"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimitChecksRejectLocally(t *testing.T) {
+	fake := newFake(t, heleketfake.WithPaymentServices([]*heleket.PaymentService{
+		{Currency: "USDT", Network: "tron", IsAvailable: true, Limit: &heleket.PaymentServiceLimit{MinAmount: "1.00000000", MaxAmount: "1000.00000000"}},
+		{Currency: "USDT", Network: "bsc", IsAvailable: false, Limit: &heleket.PaymentServiceLimit{MinAmount: "1.00000000", MaxAmount: "1000.00000000"}},
+	}))
+	client := fake.Client(heleket.WithLimitChecks(time.Minute))
+
+	_, err := client.CreateInvoice(&heleket.InvoiceRequest{Amount: "0.5", Currency: "USDT", OrderId: "small",
+		InvoiceRequestOptions: &heleket.InvoiceRequestOptions{Network: "tron"}})
+	require.ErrorIs(t, err, heleket.ErrAmountOutOfRange)
+
+	var limitErr *heleket.LimitError
+	require.True(t, errors.As(err, &limitErr))
+	require.Equal(t, "1.00000000", limitErr.Min.String())
+	require.Equal(t, "1000.00000000", limitErr.Max.String())
+	require.Equal(t, "heleket: payment amount 0.5 USDT on tron is outside the allowed range 1.00000000 to 1000.00000000", err.Error())
+
+	_, err = client.CreateInvoice(&heleket.InvoiceRequest{Amount: "10", Currency: "USDT", OrderId: "disabled",
+		InvoiceRequestOptions: &heleket.InvoiceRequestOptions{Network: "bsc"}})
+	require.ErrorIs(t, err, heleket.ErrServiceUnavailable)
+
+	_, err = client.CreateInvoice(&heleket.InvoiceRequest{Amount: "10", Currency: "USD", OrderId: "priced-in-usd",
+		InvoiceRequestOptions: &heleket.InvoiceRequestOptions{Network: "bsc", ToCurrency: "USDT"}})
+	require.ErrorIs(t, err, heleket.ErrServiceUnavailable)
+
+	invoice, err := client.CreateInvoice(&heleket.InvoiceRequest{Amount: "10", Currency: "USDT", OrderId: "ok",
+		InvoiceRequestOptions: &heleket.InvoiceRequestOptions{Network: "tron"}})
+	require.NoError(t, err)
+	require.Equal(t, "ok", invoice.OrderId)
+}
+
+func TestLimitChecksCacheServices(t *testing.T) {
+	var services atomic.Int32
+	client := newStubHeleket(t, func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/payout/services") {
+			services.Add(1)
+			w.Write([]byte(`{"state":0,"result":[{"currency":"USDT","network":"tron","is_available":true,"limit":{"min_amount":"5","max_amount":"50"}}]}`))
+			return
+		}
+		w.Write([]byte(`{"state":0,"result":{"uuid":"p1","status":"process"}}`))
+	}, heleket.WithLimitChecks(time.Hour))
+
+	for _, amount := range []string{"100", "1", "20"} {
+		_, err := client.CreatePayout(&heleket.PayoutRequest{
+			Amount: amount, Currency: "USDT", Network: "tron", OrderId: "p", Address: "TXLAQ63Xg1NAzckPwKHvzw7CSEmLMEqcdj",
+		})
+		if amount == "20" {
+			require.NoError(t, err)
+		} else {
+			require.ErrorIs(t, err, heleket.ErrAmountOutOfRange, amount)
+		}
+	}
+	require.Equal(t, int32(1), services.Load())
+}
+
+func TestLimitChecksSkippedWhenServicesFail(t *testing.T) {
+	client := newStubHeleket(t, func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/services") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Write([]byte(`{"state":0,"result":{"uuid":"u"}}`))
+	}, heleket.WithLimitChecks(0))
+
+	_, err := client.CreateInvoice(&heleket.InvoiceRequest{Amount: "10", Currency: "USDT", OrderId: "o",
+		InvoiceRequestOptions: &heleket.InvoiceRequestOptions{Network: "tron"}})
+	require.NoError(t, err)
+}
+
+func TestLimitChecksShareOneFetch(t *testing.T) {
+	var services atomic.Int32
+	started, release := make(chan struct{}), make(chan struct{})
+	client := newStubHeleket(t, func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/payout/services") {
+			if services.Add(1) == 1 {
+				close(started)
+			}
+			<-release
+			w.Write([]byte(`{"state":0,"result":[{"currency":"USDT","network":"tron","is_available":true,"limit":{"min_amount":"5","max_amount":"50"}}]}`))
+			return
+		}
+		w.Write([]byte(`{"state":0,"result":{"uuid":"p1","status":"process"}}`))
+	}, heleket.WithLimitChecks(time.Hour))
+
+	request := &heleket.PayoutRequest{Amount: "100", Currency: "USDT", Network: "tron", OrderId: "p", Address: "TXLAQ63Xg1NAzckPwKHvzw7CSEmLMEqcdj"}
+
+	// The caller that starts the fetch gives up; the fetch carries on for
+	// the caller still waiting on it.
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+	go func() {
+		_, err := client.CreatePayoutContext(ctx, request)
+		first <- err
+	}()
+	<-started
+	second := make(chan error, 1)
+	go func() {
+		_, err := client.CreatePayout(request)
+		second <- err
+	}()
+	cancel()
+	require.ErrorIs(t, <-first, context.Canceled)
+
+	close(release)
+	require.ErrorIs(t, <-second, heleket.ErrAmountOutOfRange)
+	require.Equal(t, int32(1), services.Load())
+}
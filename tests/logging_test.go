@@ -0,0 +1,69 @@
+package tests
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/idanyas/heleket-go"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoggerRedactsSecrets(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	client := newStubHeleket(t, okHandler(`{"state":0,"result":{"uuid":"inv-1","order_id":"order-1","address":"TXLAQ63Xg1NAzckPwKHvzw7CSEmLMEqcdj"}}`),
+		heleket.WithLogger(logger))
+
+	_, err := client.CreateInvoice(&heleket.InvoiceRequest{
+		Amount:   "10",
+		Currency: "USD",
+		OrderId:  "order-1",
+		InvoiceRequestOptions: &heleket.InvoiceRequestOptions{
+			PayerEmail: "john.doe@example.com",
+		},
+	})
+	require.NoError(t, err)
+
+	out := buf.String()
+	require.Contains(t, out, `"endpoint":"/payment"`)
+	require.Contains(t, out, `"order_id":"order-1"`)
+	require.Contains(t, out, `"uuid":"inv-1"`)
+	require.Contains(t, out, `"status":200`)
+	require.Contains(t, out, `j***@example.com`)
+	require.NotContains(t, out, "john.doe@example.com")
+	require.NotContains(t, out, "TXLAQ63Xg1NAzckPwKHvzw7CSEmLMEqcdj")
+	require.NotContains(t, out, "payment-key")
+}
+
+func TestLoggerLogsFailures(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+
+	client := newStubHeleket(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"state":1,"message":"Invalid Sign"}`))
+	}, heleket.WithLogger(logger))
+
+	_, err := client.GetBalance()
+	require.Error(t, err)
+	require.Contains(t, buf.String(), "level=WARN")
+	require.Contains(t, buf.String(), "status=401")
+	require.False(t, strings.Contains(buf.String(), "request="), "bodies are only logged at debug level")
+}
+
+func TestRedactJSON(t *testing.T) {
+	webhook := []byte(`{"type":"payment","from":"THgEWubVc8tPKXLJ4VZ5zbiiAK7AgqSeGH","sign":"a76c0d77f3e8e1a419b138af04ab600a","amount":"3.00000000"}`)
+	require.JSONEq(t, `{"type":"payment","from":"THgE...SeGH","sign":"[REDACTED]","amount":"3.00000000"}`, string(heleket.RedactJSON(webhook)))
+}
+
+func TestRedactJSONKeepsFirstRuneOfEmail(t *testing.T) {
+	out := heleket.RedactJSON([]byte(`{"payer_email":"élodie@example.com"}`))
+	require.True(t, utf8.Valid(out))
+	require.JSONEq(t, `{"payer_email":"é***@example.com"}`, string(out))
+}
@@ -2,21 +2,42 @@ package tests
 
 import (
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
 
 	"github.com/idanyas/heleket-go"
+	"github.com/idanyas/heleket-go/heleketfake"
 )
 
 var TestHeleket *heleket.Heleket
 
+// TestMain runs the suite against the live API when HELEKET_MERCHANT_ID,
+// HELEKET_PAYMENT_API_KEY and HELEKET_PAYOUT_API_KEY are set, and against
+// an in-process fake otherwise.
 func TestMain(m *testing.M) {
-	httpClient := http.Client{}
-	merchant := "replace with your merchant id"
-	paymentAPIKey := "replace with your payment API key"
-	payoutAPIKey := "replace with your payout API key"
+	merchant := os.Getenv("HELEKET_MERCHANT_ID")
+	paymentAPIKey := os.Getenv("HELEKET_PAYMENT_API_KEY")
+	payoutAPIKey := os.Getenv("HELEKET_PAYOUT_API_KEY")
 
-	TestHeleket = heleket.New(&httpClient, merchant, paymentAPIKey, payoutAPIKey)
+	if merchant != "" && paymentAPIKey != "" && payoutAPIKey != "" {
+		httpClient := http.Client{}
+		TestHeleket = heleket.New(&httpClient, merchant, paymentAPIKey, payoutAPIKey)
+		os.Exit(m.Run())
+	}
 
-	os.Exit(m.Run())
+	fake := heleketfake.New()
+	TestHeleket = fake.Client()
+	code := m.Run()
+	fake.Close()
+	os.Exit(code)
+}
+
+// newStubHeleket returns a client talking to an in-process server serving handler.
+func newStubHeleket(t *testing.T, handler http.HandlerFunc, opts ...heleket.Option) *heleket.Heleket {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	opts = append([]heleket.Option{heleket.WithBaseURL(server.URL + "/v1")}, opts...)
+	return heleket.New(server.Client(), "merchant", "payment-key", "payout-key", opts...)
 }
@@ -0,0 +1,61 @@
+package tests
+
+import (
+	"encoding/json"
+	"expvar"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/idanyas/heleket-go"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpvarMetrics(t *testing.T) {
+	metrics := heleket.NewExpvarMetrics("heleket_metrics_test")
+
+	var calls atomic.Int32
+	client := newStubHeleket(t, flakyHandler(&calls, 1, http.StatusBadGateway, `{"state":0,"result":[]}`),
+		heleket.WithMetrics(metrics), heleket.WithRetryPolicy(testRetryPolicy))
+
+	_, err := client.GetDiscountsList()
+	require.NoError(t, err)
+
+	failing := newStubHeleket(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		w.Write([]byte(`{"state":1,"errors":{"uuid":["required"]}}`))
+	}, heleket.WithMetrics(metrics))
+
+	_, err = failing.GetPaymentInfo(&heleket.PaymentInfoRequest{OrderId: "x"})
+	require.Error(t, err)
+
+	_, err = failing.ParseWebhook(signWebhook(`{"type":"payment"}`, "wrong-key"), true)
+	require.Error(t, err)
+
+	var vars struct {
+		Requests map[string]int            `json:"requests"`
+		Errors   map[string]map[string]int `json:"errors"`
+		Retries  map[string]int            `json:"retries"`
+		Latency  map[string]struct {
+			Count   int            `json:"count"`
+			Buckets map[string]int `json:"buckets"`
+		} `json:"latency_ms"`
+		WebhookFailures int `json:"webhook_verification_failures"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(expvar.Get("heleket_metrics_test").String()), &vars))
+
+	require.Equal(t, 1, vars.Requests["/payment/discount/list"])
+	require.Equal(t, 1, vars.Retries["/payment/discount/list"])
+	require.Equal(t, 1, vars.Errors["/payment/info"][heleket.ErrorClassValidation])
+	require.Equal(t, 1, vars.Latency["/payment/info"].Count)
+	require.Equal(t, 1, vars.Latency["/payment/info"].Buckets["+Inf"])
+	require.Equal(t, 1, vars.WebhookFailures)
+}
+
+func TestErrorClass(t *testing.T) {
+	require.Equal(t, heleket.ErrorClassNone, heleket.ErrorClass(nil))
+	require.Equal(t, heleket.ErrorClassServer, heleket.ErrorClass(&heleket.APIError{StatusCode: http.StatusInternalServerError}))
+	require.Equal(t, heleket.ErrorClassRateLimited, heleket.ErrorClass(&heleket.APIError{StatusCode: http.StatusTooManyRequests}))
+	require.Equal(t, heleket.ErrorClassClient, heleket.ErrorClass(&heleket.APIError{StatusCode: http.StatusBadRequest}))
+}
@@ -0,0 +1,79 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/idanyas/heleket-go"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddlewareSeesCall(t *testing.T) {
+	var seen []string
+	record := func(name string) heleket.Middleware {
+		return func(next heleket.Doer) heleket.Doer {
+			return heleket.DoerFunc(func(ctx context.Context, req *heleket.Request) (*heleket.Response, error) {
+				seen = append(seen, name+":"+req.Endpoint+":"+req.KeyKind.String())
+				req.Header.Set("X-Trace", name)
+				res, err := next.Do(ctx, req)
+				require.NoError(t, err)
+				require.Equal(t, http.StatusOK, res.StatusCode)
+				require.Equal(t, int8(0), res.State)
+				return res, err
+			})
+		}
+	}
+
+	client := newStubHeleket(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "inner", r.Header.Get("X-Trace"))
+		w.Write([]byte(`{"state":0,"result":{"uuid":"p1","status":"process"}}`))
+	}, heleket.WithMiddleware(record("outer"), record("inner")))
+
+	payout, err := client.CreatePayout(&heleket.PayoutRequest{Amount: "5", Currency: "USDT", OrderId: "o1", Address: "TXLAQ63Xg1NAzckPwKHvzw7CSEmLMEqcdj", Network: "tron"})
+	require.NoError(t, err)
+	require.Equal(t, "p1", payout.UUID)
+	require.Equal(t, []string{"outer:/payout:payout", "inner:/payout:payout"}, seen)
+}
+
+func TestMiddlewareReceivesBodyAndFailures(t *testing.T) {
+	var body string
+	var failure error
+	client := newStubHeleket(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		w.Write([]byte(`{"state":1,"errors":{"uuid":["required"]}}`))
+	}, heleket.WithMiddleware(func(next heleket.Doer) heleket.Doer {
+		return heleket.DoerFunc(func(ctx context.Context, req *heleket.Request) (*heleket.Response, error) {
+			body = string(req.Body)
+			res, err := next.Do(ctx, req)
+			failure = err
+			return res, err
+		})
+	}))
+
+	_, err := client.GetPaymentInfo(&heleket.PaymentInfoRequest{OrderId: "o1"})
+	require.ErrorIs(t, err, heleket.ErrValidation)
+	require.ErrorIs(t, failure, heleket.ErrValidation)
+	require.JSONEq(t, `{"order_id":"o1"}`, body)
+}
+
+func TestMiddlewareFaultInjectionIsRetried(t *testing.T) {
+	calls := 0
+	inject := func(next heleket.Doer) heleket.Doer {
+		return heleket.DoerFunc(func(ctx context.Context, req *heleket.Request) (*heleket.Response, error) {
+			calls++
+			if req.Attempt == 1 {
+				return &heleket.Response{StatusCode: http.StatusServiceUnavailable, Header: http.Header{}}, &heleket.APIError{StatusCode: http.StatusServiceUnavailable}
+			}
+			return next.Do(ctx, req)
+		})
+	}
+
+	client := newStubHeleket(t, okHandler(`{"state":0,"result":[]}`),
+		heleket.WithMiddleware(inject), heleket.WithRetryPolicy(testRetryPolicy))
+
+	_, err := client.GetPaymentServicesList()
+	require.NoError(t, err)
+	require.Equal(t, 2, calls)
+}
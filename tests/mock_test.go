@@ -0,0 +1,49 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/idanyas/heleket-go"
+	"github.com/idanyas/heleket-go/heleketmock"
+
+	"github.com/stretchr/testify/require"
+)
+
+// checkout depends only on the narrow interface it needs.
+func checkout(payments heleket.PaymentsAPI, orderId string) (string, error) {
+	invoice, err := payments.CreateInvoice(&heleket.InvoiceRequest{Amount: "10", Currency: "USD", OrderId: orderId})
+	if err != nil {
+		return "", err
+	}
+	return invoice.Url, nil
+}
+
+func TestMockStubsAndRecordsCalls(t *testing.T) {
+	mock := &heleketmock.Client{
+		CreateInvoiceFunc: func(ctx context.Context, req *heleket.InvoiceRequest) (*heleket.Payment, error) {
+			return &heleket.Payment{OrderId: req.OrderId, Url: "https://pay.heleket.com/pay/" + req.OrderId}, nil
+		},
+	}
+
+	url, err := checkout(mock, "order-1")
+	require.NoError(t, err)
+	require.Equal(t, "https://pay.heleket.com/pay/order-1", url)
+
+	calls := mock.CallsTo("CreateInvoice")
+	require.Len(t, calls, 1)
+	require.Equal(t, "order-1", calls[0].Args[0].(*heleket.InvoiceRequest).OrderId)
+
+	_, err = mock.GetBalanceContext(context.Background())
+	require.ErrorIs(t, err, heleketmock.ErrNotStubbed)
+	require.Len(t, mock.Calls(), 2)
+
+	mock.Reset()
+	require.Empty(t, mock.Calls())
+}
+
+func TestHeleketImplementsClient(t *testing.T) {
+	var client heleket.Client = TestHeleket
+	_, err := checkout(client, "order-interface")
+	require.NoError(t, err)
+}
@@ -0,0 +1,40 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/idanyas/heleket-go"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptionsBaseURLAndHeaders(t *testing.T) {
+	client := newStubHeleket(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v1/balance", r.URL.Path)
+		require.Equal(t, "heleket-test/1.0", r.Header.Get("User-Agent"))
+		require.Equal(t, "abc", r.Header.Get("X-Request-Id"))
+		require.Equal(t, "merchant", r.Header.Get("merchant"))
+		require.NotEmpty(t, r.Header.Get("sign"))
+		w.Write([]byte(`{"state":0,"result":[{"balance":{"merchant":[{"uuid":"u","balance":"1.00","currency_code":"USDT"}],"user":[]}}]}`))
+	}, heleket.WithUserAgent("heleket-test/1.0"), heleket.WithHeader("X-Request-Id", "abc"))
+
+	balance, err := client.GetBalance()
+	require.NoError(t, err)
+	require.Len(t, balance.Merchant, 1)
+	require.Equal(t, "1.00", balance.Merchant[0].Balance)
+}
+
+func TestOptionsDefaultTimeout(t *testing.T) {
+	client := newStubHeleket(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(200 * time.Millisecond):
+		}
+	}, heleket.WithDefaultTimeout(20*time.Millisecond))
+
+	_, err := client.GetBalance()
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
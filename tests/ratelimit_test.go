@@ -0,0 +1,74 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/idanyas/heleket-go"
+
+	"github.com/stretchr/testify/require"
+)
+
+func okHandler(body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}
+}
+
+func TestRateLimiterDelaysCalls(t *testing.T) {
+	var mu sync.Mutex
+	var delays []time.Duration
+
+	client := newStubHeleket(t, okHandler(`{"state":0,"result":[]}`), heleket.WithRateLimits(heleket.RateLimits{
+		Payment: heleket.RateLimit{Rate: 20, Burst: 1},
+		OnDelay: func(endpoint string, keyKind heleket.KeyKind, delay time.Duration) {
+			require.Equal(t, "/payment/discount/list", endpoint)
+			require.Equal(t, heleket.PaymentKey, keyKind)
+			mu.Lock()
+			delays = append(delays, delay)
+			mu.Unlock()
+		},
+	}))
+
+	start := time.Now()
+	for range 3 {
+		_, err := client.GetDiscountsList()
+		require.NoError(t, err)
+	}
+
+	require.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+	require.Len(t, delays, 2)
+}
+
+func TestRateLimiterSeparatesKeys(t *testing.T) {
+	client := newStubHeleket(t, okHandler(`{"state":0,"result":[]}`), heleket.WithRateLimits(heleket.RateLimits{
+		Payment: heleket.RateLimit{Rate: 0.001, Burst: 1},
+		OnDelay: func(string, heleket.KeyKind, time.Duration) {
+			t.Fatal("payout calls must not wait for the payment bucket")
+		},
+	}))
+
+	_, err := client.GetDiscountsList()
+	require.NoError(t, err)
+	_, err = client.GetPayoutServicesList()
+	require.NoError(t, err)
+}
+
+func TestRateLimiterHonoursContext(t *testing.T) {
+	client := newStubHeleket(t, okHandler(`{"state":0,"result":{"items":[],"paginate":{}}}`), heleket.WithRateLimits(heleket.RateLimits{
+		Groups: map[heleket.EndpointGroup]heleket.RateLimit{
+			heleket.GroupHistory: {Rate: 0.001, Burst: 1},
+		},
+	}))
+
+	_, err := client.GetPaymentHistory(time.Now(), time.Now(), "")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = client.GetPayoutHistoryContext(ctx, time.Now(), time.Now(), "")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
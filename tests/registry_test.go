@@ -0,0 +1,81 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/idanyas/heleket-go"
+	"github.com/idanyas/heleket-go/heleketfake"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistryLookups(t *testing.T) {
+	registry := heleket.NewRegistry()
+
+	usdt, ok := registry.Currency("usdt")
+	require.True(t, ok)
+	require.Equal(t, "USDT", usdt.Code)
+	require.Equal(t, int32(6), usdt.Precision)
+	require.True(t, usdt.Supports("TRON"))
+
+	tron, ok := registry.Network("tron")
+	require.True(t, ok)
+	require.Equal(t, heleket.MemoNone, tron.Memo)
+	require.Equal(t, "https://tronscan.org/#/transaction/abc", tron.TxURL("abc"))
+	require.NoError(t, tron.ValidateAddress("TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t"))
+	require.ErrorIs(t, tron.ValidateAddress("0x52908400098527886E0F7030069857D2E4169EE7"), heleket.ErrInvalidAddress)
+
+	ton, ok := registry.Network("ton")
+	require.True(t, ok)
+	require.Equal(t, heleket.MemoOptional, ton.Memo)
+
+	require.Equal(t, int32(2), heleket.CurrencyPrecision("usd"))
+	require.Equal(t, int32(8), heleket.CurrencyPrecision("UNKNOWN"))
+}
+
+func TestRegistryChecks(t *testing.T) {
+	registry := heleket.NewRegistry()
+
+	require.NoError(t, registry.CheckPair("USDT", "tron"))
+	require.NoError(t, registry.CheckPair("USD", "bsc"))
+
+	err := registry.CheckNetwork("trc20")
+	require.ErrorIs(t, err, heleket.ErrUnknownNetwork)
+	require.Contains(t, err.Error(), `did you mean "tron"`)
+
+	require.ErrorIs(t, registry.CheckPair("BTC", "tron"), heleket.ErrUnsupportedNetwork)
+	require.ErrorIs(t, registry.CheckPair("XYZ", "tron"), heleket.ErrUnknownCurrency)
+	require.ErrorIs(t, registry.CheckCurrency("XYZ"), heleket.ErrUnknownCurrency)
+}
+
+func TestRegistryRefresh(t *testing.T) {
+	fake := newFake(t,
+		heleketfake.WithPaymentServices([]*heleket.PaymentService{
+			{Currency: "USDT", Network: "tron", IsAvailable: true},
+			{Currency: "BTC", Network: "btc", IsAvailable: false},
+			{Currency: "NEW", Network: "newchain", IsAvailable: true},
+		}),
+		heleketfake.WithPayoutServices([]*heleket.PayoutService{
+			{Currency: "USDT", Network: "tron", IsAvailable: true},
+		}),
+	)
+	registry := heleket.NewRegistry()
+	require.NoError(t, registry.Refresh(context.Background(), fake.Client()))
+
+	available, known := registry.Available(heleket.PaymentKey, "usdt", "TRON")
+	require.True(t, known)
+	require.True(t, available)
+
+	available, known = registry.Available(heleket.PaymentKey, "BTC", "btc")
+	require.True(t, known)
+	require.False(t, available)
+
+	_, known = registry.Available(heleket.PayoutKey, "BTC", "btc")
+	require.False(t, known)
+
+	require.NoError(t, registry.CheckPair("NEW", "newchain"))
+	info, ok := registry.Currency("NEW")
+	require.True(t, ok)
+	require.Equal(t, int32(8), info.Precision)
+}
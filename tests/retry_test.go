@@ -0,0 +1,85 @@
+package tests
+
+import (
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/idanyas/heleket-go"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testRetryPolicy = heleket.RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: time.Millisecond,
+	MaxBackoff:     10 * time.Millisecond,
+}
+
+// flakyHandler fails the first failures requests with status and then succeeds with body.
+func flakyHandler(calls *atomic.Int32, failures int32, status int, body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= failures {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(status)
+			return
+		}
+		w.Write([]byte(body))
+	}
+}
+
+func TestRetryIdempotentCall(t *testing.T) {
+	var calls atomic.Int32
+	client := newStubHeleket(t, flakyHandler(&calls, 2, http.StatusServiceUnavailable, `{"state":0,"result":[]}`),
+		heleket.WithRetryPolicy(testRetryPolicy))
+
+	_, err := client.GetDiscountsList()
+	require.NoError(t, err)
+	require.Equal(t, int32(3), calls.Load())
+}
+
+func TestRetryGivesUpAfterMaxAttempts(t *testing.T) {
+	var calls atomic.Int32
+	client := newStubHeleket(t, flakyHandler(&calls, 5, http.StatusTooManyRequests, `{"state":0,"result":[]}`),
+		heleket.WithRetryPolicy(testRetryPolicy))
+
+	_, err := client.GetDiscountsList()
+	require.ErrorIs(t, err, heleket.ErrRateLimited)
+	require.Equal(t, int32(3), calls.Load())
+}
+
+func TestRetryMutatingCallRequiresOptIn(t *testing.T) {
+	invoice := &heleket.InvoiceRequest{Amount: "10", Currency: "USD", OrderId: "order-1"}
+	body := `{"state":0,"result":{"uuid":"u","order_id":"order-1"}}`
+
+	var calls atomic.Int32
+	client := newStubHeleket(t, flakyHandler(&calls, 1, http.StatusBadGateway, body),
+		heleket.WithRetryPolicy(testRetryPolicy))
+
+	_, err := client.CreateInvoice(invoice)
+	require.Error(t, err)
+	require.Equal(t, int32(1), calls.Load())
+
+	policy := testRetryPolicy
+	policy.RetryMutating = true
+
+	calls.Store(0)
+	client = newStubHeleket(t, flakyHandler(&calls, 1, http.StatusBadGateway, body),
+		heleket.WithRetryPolicy(policy))
+
+	payment, err := client.CreateInvoice(invoice)
+	require.NoError(t, err)
+	require.Equal(t, "order-1", payment.OrderId)
+	require.Equal(t, int32(2), calls.Load())
+}
+
+func TestRetryDoesNotRepeatValidationErrors(t *testing.T) {
+	var calls atomic.Int32
+	client := newStubHeleket(t, flakyHandler(&calls, 5, http.StatusUnprocessableEntity, ``),
+		heleket.WithRetryPolicy(testRetryPolicy))
+
+	_, err := client.GetPaymentInfo(&heleket.PaymentInfoRequest{OrderId: "x"})
+	require.ErrorIs(t, err, heleket.ErrValidation)
+	require.Equal(t, int32(1), calls.Load())
+}
@@ -0,0 +1,150 @@
+package tests
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/idanyas/heleket-go"
+	"github.com/idanyas/heleket-go/heleketfake"
+
+	"github.com/stretchr/testify/require"
+)
+
+// webhookSink collects webhooks verified by client.
+type webhookSink struct {
+	mu       sync.Mutex
+	webhooks []*heleket.Webhook
+}
+
+func newWebhookSink(t *testing.T, client *heleket.Heleket) (*webhookSink, string) {
+	sink := &webhookSink{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		webhook, err := client.ParseWebhook(body, true)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		sink.mu.Lock()
+		sink.webhooks = append(sink.webhooks, webhook)
+		sink.mu.Unlock()
+	}))
+	t.Cleanup(server.Close)
+	return sink, server.URL
+}
+
+func (s *webhookSink) statuses() []heleket.Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	statuses := make([]heleket.Status, len(s.webhooks))
+	for i, webhook := range s.webhooks {
+		statuses[i] = webhook.Status
+	}
+	return statuses
+}
+
+func TestSimulatorScripts(t *testing.T) {
+	cases := map[string]struct {
+		script   heleketfake.Script
+		statuses []heleket.Status
+	}{
+		"paid":         {heleketfake.ScriptPaid, []heleket.Status{"confirm_check", "paid"}},
+		"paid_over":    {heleketfake.ScriptPaidOver, []heleket.Status{"confirm_check", "paid_over"}},
+		"wrong_amount": {heleketfake.ScriptWrongAmount, []heleket.Status{"confirm_check", "wrong_amount"}},
+		"cancel":       {heleketfake.ScriptCancel, []heleket.Status{"cancel"}},
+		"fail":         {heleketfake.ScriptFail, []heleket.Status{"confirm_check", "fail"}},
+		"refund":       {heleketfake.ScriptRefund, []heleket.Status{"confirm_check", "paid", "refund_process", "refund_paid"}},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			clock := heleketfake.NewClock(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+			fake := newFake(t, heleketfake.WithClock(clock.Now))
+			client := fake.Client()
+			sink, callback := newWebhookSink(t, client)
+
+			invoice, err := client.CreateInvoice(&heleket.InvoiceRequest{
+				Amount:                "3",
+				Currency:              "TRX",
+				OrderId:               "order-" + name,
+				InvoiceRequestOptions: &heleket.InvoiceRequestOptions{UrlCallback: callback},
+			})
+			require.NoError(t, err)
+
+			simulator := heleketfake.NewSimulator(fake, clock)
+			require.NoError(t, simulator.Run(invoice.UUID, tc.script))
+
+			deliveries := simulator.Advance(context.Background(), 3*time.Hour)
+			for _, delivery := range deliveries {
+				require.NoError(t, delivery.Err)
+				require.Equal(t, http.StatusOK, delivery.StatusCode)
+			}
+			require.Equal(t, tc.statuses, sink.statuses())
+			require.Zero(t, simulator.Pending())
+
+			final := tc.statuses[len(tc.statuses)-1]
+			info, err := client.GetPaymentInfo(&heleket.PaymentInfoRequest{PaymentUUID: invoice.UUID})
+			require.NoError(t, err)
+			require.Equal(t, final, info.PaymentStatus)
+			require.True(t, info.IsFinal)
+		})
+	}
+}
+
+func TestSimulatorFollowsClock(t *testing.T) {
+	clock := heleketfake.NewClock(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	fake := newFake(t, heleketfake.WithClock(clock.Now))
+	client := fake.Client()
+	sink, callback := newWebhookSink(t, client)
+
+	invoice, err := client.CreateInvoice(&heleket.InvoiceRequest{
+		Amount:                "3",
+		Currency:              "TRX",
+		OrderId:               "order-1",
+		InvoiceRequestOptions: &heleket.InvoiceRequestOptions{UrlCallback: callback},
+	})
+	require.NoError(t, err)
+
+	simulator := heleketfake.NewSimulator(fake, clock)
+	require.NoError(t, simulator.Run(invoice.UUID, heleketfake.ScriptPaid))
+
+	require.Empty(t, simulator.Advance(context.Background(), 30*time.Second))
+	require.Len(t, simulator.Advance(context.Background(), time.Minute), 1)
+	require.Equal(t, []heleket.Status{"confirm_check"}, sink.statuses())
+
+	simulator.Advance(context.Background(), 10*time.Minute)
+	sink.mu.Lock()
+	paid := sink.webhooks[1]
+	sink.mu.Unlock()
+	require.Equal(t, "3.00000000", paid.PaymentAmount)
+	require.Equal(t, "2.94000000", paid.MerchantAmount)
+	require.Equal(t, "0.06000000", paid.Commission)
+	require.True(t, paid.IsFinal)
+	require.Equal(t, clock.Now(), time.Date(2025, 1, 1, 12, 11, 30, 0, time.UTC))
+
+	require.Error(t, simulator.Run("missing", heleketfake.ScriptPaid))
+}
+
+func TestSimulatorRejectsInvalidScript(t *testing.T) {
+	clock := heleketfake.NewClock(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	fake := newFake(t, heleketfake.WithClock(clock.Now))
+	client := fake.Client()
+
+	invoice, err := client.CreateInvoice(&heleket.InvoiceRequest{Amount: "3", Currency: "TRX", OrderId: "order-1"})
+	require.NoError(t, err)
+
+	simulator := heleketfake.NewSimulator(fake, clock)
+	err = simulator.Run(invoice.UUID, heleketfake.Script{
+		{After: time.Minute, Status: heleket.StatusConfirmCheck},
+		{After: 2 * time.Minute, Status: "settled"},
+	})
+	require.ErrorContains(t, err, `unknown status "settled"`)
+	require.Zero(t, simulator.Pending())
+}
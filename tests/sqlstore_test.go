@@ -0,0 +1,361 @@
+package tests
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/idanyas/heleket-go"
+	"github.com/idanyas/heleket-go/heleketsync"
+
+	"github.com/stretchr/testify/require"
+)
+
+// memDB is a database/sql driver that understands the statements SQLStore
+// issues. It keeps tables in memory, records every query and can run a
+// hook after each SELECT COUNT(*) to stand in for a concurrent writer.
+// Transactions do not isolate, like READ COMMITTED for new rows.
+type memDB struct {
+	mu         sync.Mutex
+	tables     map[string]*memTable
+	queries    []string
+	afterCount func(table, key string)
+}
+
+type memTable struct {
+	key  string
+	rows map[string]map[string]string
+}
+
+func newMemDB() *memDB {
+	return &memDB{tables: map[string]*memTable{}}
+}
+
+func (db *memDB) Connect(context.Context) (driver.Conn, error) { return memConn{db}, nil }
+func (db *memDB) Driver() driver.Driver                        { return memDriver{db} }
+
+// insert adds a row directly, bypassing SQL.
+func (db *memDB) insert(table string, row map[string]string) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	t := db.tables[table]
+	t.rows[row[t.key]] = row
+}
+
+func (db *memDB) recorded() []string {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return slices.Clone(db.queries)
+}
+
+type memDriver struct{ db *memDB }
+
+func (d memDriver) Open(string) (driver.Conn, error) { return memConn{d.db}, nil }
+
+type memConn struct{ db *memDB }
+
+func (c memConn) Prepare(string) (driver.Stmt, error) {
+	return nil, fmt.Errorf("memdb: prepared statements are not supported")
+}
+func (c memConn) Close() error              { return nil }
+func (c memConn) Begin() (driver.Tx, error) { return memTx{}, nil }
+
+type memTx struct{}
+
+func (memTx) Commit() error   { return nil }
+func (memTx) Rollback() error { return nil }
+
+var (
+	memPlaceholder = `(\?|\$\d+)`
+	memCreate      = regexp.MustCompile(`^CREATE TABLE IF NOT EXISTS (\w+) \( (\w+) `)
+	memCount       = regexp.MustCompile(`^SELECT COUNT\(\*\) FROM (\w+) WHERE (\w+) = ` + memPlaceholder + `$`)
+	memSelect      = regexp.MustCompile(`^SELECT (\w+) FROM (\w+) WHERE (\w+) = ` + memPlaceholder + `$`)
+	memList        = regexp.MustCompile(`^SELECT (\w+) FROM (\w+) ORDER BY (\w+), (\w+)$`)
+	memUpdate      = regexp.MustCompile(`^UPDATE (\w+) SET (.+) WHERE (\w+) = ` + memPlaceholder + `$`)
+	memInsert      = regexp.MustCompile(`^INSERT INTO (\w+) \((.+)\) VALUES \((.+)\)$`)
+	memAssign      = regexp.MustCompile(`^(\w+) = ` + memPlaceholder + `$`)
+)
+
+func (c memConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	db := c.db
+	q := db.record(query)
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if m := memCreate.FindStringSubmatch(q); m != nil {
+		if _, ok := db.tables[m[1]]; !ok {
+			db.tables[m[1]] = &memTable{key: m[2], rows: map[string]map[string]string{}}
+		}
+		return driver.RowsAffected(0), nil
+	}
+	if m := memUpdate.FindStringSubmatch(q); m != nil {
+		t, err := db.table(m[1], m[3])
+		if err != nil {
+			return nil, err
+		}
+		row, ok := t.rows[value(args[len(args)-1])]
+		if !ok {
+			return driver.RowsAffected(0), nil
+		}
+		for i, assign := range strings.Split(m[2], ", ") {
+			a := memAssign.FindStringSubmatch(assign)
+			if a == nil {
+				return nil, fmt.Errorf("memdb: cannot parse %q", assign)
+			}
+			row[a[1]] = value(args[i])
+		}
+		return driver.RowsAffected(1), nil
+	}
+	if m := memInsert.FindStringSubmatch(q); m != nil {
+		t, ok := db.tables[m[1]]
+		if !ok {
+			return nil, fmt.Errorf("memdb: no table %s", m[1])
+		}
+		row := map[string]string{}
+		for i, column := range strings.Split(m[2], ", ") {
+			row[column] = value(args[i])
+		}
+		if _, dup := t.rows[row[t.key]]; dup {
+			return nil, fmt.Errorf("memdb: duplicate key %q in %s", row[t.key], m[1])
+		}
+		t.rows[row[t.key]] = row
+		return driver.RowsAffected(1), nil
+	}
+	return nil, fmt.Errorf("memdb: cannot execute %q", q)
+}
+
+func (c memConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	db := c.db
+	q := db.record(query)
+
+	if m := memCount.FindStringSubmatch(q); m != nil {
+		db.mu.Lock()
+		t, err := db.table(m[1], m[2])
+		if err != nil {
+			db.mu.Unlock()
+			return nil, err
+		}
+		key := value(args[0])
+		var n int64
+		if _, ok := t.rows[key]; ok {
+			n = 1
+		}
+		hook := db.afterCount
+		db.mu.Unlock()
+		if hook != nil {
+			hook(m[1], key)
+		}
+		return &memRows{columns: []string{"count"}, values: [][]driver.Value{{n}}}, nil
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if m := memSelect.FindStringSubmatch(q); m != nil {
+		t, err := db.table(m[2], m[3])
+		if err != nil {
+			return nil, err
+		}
+		rows := &memRows{columns: []string{m[1]}}
+		if row, ok := t.rows[value(args[0])]; ok {
+			rows.values = append(rows.values, []driver.Value{row[m[1]]})
+		}
+		return rows, nil
+	}
+	if m := memList.FindStringSubmatch(q); m != nil {
+		t, ok := db.tables[m[2]]
+		if !ok {
+			return nil, fmt.Errorf("memdb: no table %s", m[2])
+		}
+		sorted := slices.SortedFunc(maps.Values(t.rows), func(a, b map[string]string) int {
+			if c := strings.Compare(a[m[3]], b[m[3]]); c != 0 {
+				return c
+			}
+			return strings.Compare(a[m[4]], b[m[4]])
+		})
+		rows := &memRows{columns: []string{m[1]}}
+		for _, row := range sorted {
+			rows.values = append(rows.values, []driver.Value{row[m[1]]})
+		}
+		return rows, nil
+	}
+	return nil, fmt.Errorf("memdb: cannot query %q", q)
+}
+
+// record normalises the whitespace of query and appends it to the log.
+func (db *memDB) record(query string) string {
+	q := strings.Join(strings.Fields(query), " ")
+	db.mu.Lock()
+	db.queries = append(db.queries, q)
+	db.mu.Unlock()
+	return q
+}
+
+func (db *memDB) table(name, key string) (*memTable, error) {
+	t, ok := db.tables[name]
+	if !ok {
+		return nil, fmt.Errorf("memdb: no table %s", name)
+	}
+	if t.key != key {
+		return nil, fmt.Errorf("memdb: %s is keyed by %s, not %s", name, t.key, key)
+	}
+	return t, nil
+}
+
+func value(arg driver.NamedValue) string {
+	return fmt.Sprint(arg.Value)
+}
+
+type memRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *memRows) Columns() []string { return r.columns }
+func (r *memRows) Close() error      { return nil }
+
+func (r *memRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+func newSQLStore(t *testing.T, opts ...heleketsync.SQLOption) (*heleketsync.SQLStore, *memDB) {
+	t.Helper()
+	mem := newMemDB()
+	db := sql.OpenDB(mem)
+	t.Cleanup(func() { db.Close() })
+	store := heleketsync.NewSQLStore(db, opts...)
+	require.NoError(t, store.Migrate(context.Background()))
+	return store, mem
+}
+
+func TestSQLStoreRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	store, mem := newSQLStore(t)
+	require.NoError(t, store.Migrate(ctx), "Migrate is idempotent")
+	require.ElementsMatch(t, []string{"heleket_payments", "heleket_payouts", "heleket_sync_marks"}, slices.Collect(maps.Keys(mem.tables)))
+
+	_, err := store.GetPayment(ctx, "p1")
+	require.ErrorIs(t, err, heleketsync.ErrNotFound)
+
+	created := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, store.PutPayment(ctx, &heleket.Payment{UUID: "p2", CreatedAt: created.Add(time.Second), PaymentStatus: heleket.StatusCheck}))
+	require.NoError(t, store.PutPayment(ctx, &heleket.Payment{UUID: "p1", CreatedAt: created, PaymentStatus: heleket.StatusCheck}))
+	require.NoError(t, store.PutPayment(ctx, &heleket.Payment{UUID: "p1", CreatedAt: created, PaymentStatus: heleket.StatusPaid, IsFinal: true}))
+
+	payment, err := store.GetPayment(ctx, "p1")
+	require.NoError(t, err)
+	require.Equal(t, heleket.StatusPaid, payment.PaymentStatus)
+	require.True(t, payment.IsFinal)
+	require.Equal(t, "paid", mem.tables["heleket_payments"].rows["p1"]["status"])
+	require.Equal(t, "2025-03-01T12:00:00.000000000Z", mem.tables["heleket_payments"].rows["p1"]["created_at"])
+
+	var uuids []string
+	for payment, err := range store.Payments(ctx) {
+		require.NoError(t, err)
+		uuids = append(uuids, payment.UUID)
+	}
+	require.Equal(t, []string{"p1", "p2"}, uuids)
+	for payment, err := range store.Payments(ctx) {
+		require.NoError(t, err)
+		require.Equal(t, "p1", payment.UUID)
+		break
+	}
+
+	require.NoError(t, store.PutPayout(ctx, &heleket.Payout{UUID: "o1", CreatedAt: created, Status: heleket.StatusProcess, Amount: "5"}))
+	require.NoError(t, store.PutPayout(ctx, &heleket.Payout{UUID: "o1", CreatedAt: created, Status: heleket.StatusPaid, Amount: "5"}))
+	payout, err := store.GetPayout(ctx, "o1")
+	require.NoError(t, err)
+	require.Equal(t, heleket.StatusPaid, payout.Status)
+	count := 0
+	for _, err := range store.Payouts(ctx) {
+		require.NoError(t, err)
+		count++
+	}
+	require.Equal(t, 1, count)
+
+	// Corrupt data is reported rather than skipped.
+	mem.insert("heleket_payouts", map[string]string{"uuid": "o2", "created_at": "2025-03-02T00:00:00.000000000Z", "data": "{"})
+	var errs []error
+	for _, err := range store.Payouts(ctx) {
+		errs = append(errs, err)
+	}
+	require.Len(t, errs, 2)
+	require.NoError(t, errs[0])
+	require.Error(t, errs[1])
+}
+
+func TestSQLStoreMarks(t *testing.T) {
+	ctx := context.Background()
+	store, mem := newSQLStore(t)
+
+	mark, err := store.Mark(ctx, heleketsync.KindPayment)
+	require.NoError(t, err)
+	require.True(t, mark.IsZero())
+
+	// Marks keep nanoseconds and come back in UTC.
+	at := time.Date(2025, 3, 1, 15, 4, 5, 123456789, time.FixedZone("UTC+3", 3*3600))
+	require.NoError(t, store.SetMark(ctx, heleketsync.KindPayment, at))
+	require.NoError(t, store.SetMark(ctx, heleketsync.KindPayment, at.Add(time.Nanosecond)))
+	mark, err = store.Mark(ctx, heleketsync.KindPayment)
+	require.NoError(t, err)
+	require.Equal(t, at.Add(time.Nanosecond).UTC(), mark)
+
+	mark, err = store.Mark(ctx, heleketsync.KindPayout)
+	require.NoError(t, err)
+	require.True(t, mark.IsZero())
+
+	mem.insert("heleket_sync_marks", map[string]string{"kind": "payout", "mark": "yesterday"})
+	_, err = store.Mark(ctx, heleketsync.KindPayout)
+	require.ErrorContains(t, err, "heleketsync: read payout mark")
+}
+
+func TestSQLStoreDollarPlaceholders(t *testing.T) {
+	ctx := context.Background()
+	store, mem := newSQLStore(t, heleketsync.WithDollarPlaceholders(), heleketsync.WithTablePrefix("app_"))
+
+	require.NoError(t, store.PutPayment(ctx, &heleket.Payment{UUID: "p1", PaymentStatus: heleket.StatusCheck}))
+	require.NoError(t, store.SetMark(ctx, heleketsync.KindPayment, time.Now()))
+	_, err := store.GetPayment(ctx, "p1")
+	require.NoError(t, err)
+
+	queries := mem.recorded()
+	require.Contains(t, queries, "INSERT INTO app_payments (status, created_at, data, uuid) VALUES ($1, $2, $3, $4)")
+	require.Contains(t, queries, "SELECT COUNT(*) FROM app_sync_marks WHERE kind = $1")
+	require.Contains(t, queries, "SELECT data FROM app_payments WHERE uuid = $1")
+	for _, q := range queries {
+		require.NotContains(t, q, "?")
+		require.NotContains(t, q, "heleket_")
+	}
+}
+
+func TestSQLStoreUpsertRetriesRacingInsert(t *testing.T) {
+	ctx := context.Background()
+	store, mem := newSQLStore(t)
+
+	// Another writer inserts the row between the count and the insert.
+	var once sync.Once
+	mem.afterCount = func(table, key string) {
+		once.Do(func() {
+			mem.insert(table, map[string]string{"uuid": key, "status": "check", "created_at": "", "data": `{"uuid":"p1"}`})
+		})
+	}
+	require.NoError(t, store.PutPayment(ctx, &heleket.Payment{UUID: "p1", PaymentStatus: heleket.StatusPaid}))
+
+	payment, err := store.GetPayment(ctx, "p1")
+	require.NoError(t, err)
+	require.Equal(t, heleket.StatusPaid, payment.PaymentStatus)
+	require.Equal(t, "paid", mem.tables["heleket_payments"].rows["p1"]["status"])
+}
@@ -0,0 +1,58 @@
+package tests
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/idanyas/heleket-go"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatusClassification(t *testing.T) {
+	cases := []struct {
+		status                                    heleket.Status
+		final, successful, refund, requiresAction bool
+	}{
+		{heleket.StatusCheck, false, false, false, false},
+		{heleket.StatusConfirmCheck, false, false, false, false},
+		{heleket.StatusPaid, true, true, false, false},
+		{heleket.StatusPaidOver, true, true, false, false},
+		{heleket.StatusWrongAmount, true, false, false, true},
+		{heleket.StatusCancel, true, false, false, false},
+		{heleket.StatusSystemFail, true, false, false, true},
+		{heleket.StatusRefundProcess, false, false, true, false},
+		{heleket.StatusRefundPaid, true, false, true, false},
+		{heleket.StatusRefundFail, true, false, true, true},
+		{heleket.StatusLocked, false, false, false, true},
+	}
+	for _, tc := range cases {
+		require.True(t, tc.status.IsKnown(), tc.status)
+		require.Equal(t, tc.final, tc.status.IsFinal(), tc.status)
+		require.Equal(t, tc.successful, tc.status.IsSuccessful(), tc.status)
+		require.Equal(t, tc.refund, tc.status.IsRefund(), tc.status)
+		require.Equal(t, tc.requiresAction, tc.status.RequiresAction(), tc.status)
+	}
+}
+
+func TestUnknownStatusIsPreserved(t *testing.T) {
+	var payment heleket.Payment
+	require.NoError(t, json.Unmarshal([]byte(`{"payment_status":"partially_paid","status":"paid"}`), &payment))
+
+	require.Equal(t, heleket.Status("partially_paid"), payment.PaymentStatus)
+	require.False(t, payment.PaymentStatus.IsKnown())
+	require.False(t, payment.PaymentStatus.IsFinal())
+	require.Equal(t, heleket.StatusPaid, payment.Status)
+
+	out, err := json.Marshal(&payment)
+	require.NoError(t, err)
+	require.Contains(t, string(out), `"payment_status":"partially_paid"`)
+}
+
+func TestWebhookTypeIsTyped(t *testing.T) {
+	webhook, err := TestHeleket.ParseWebhook([]byte(`{"type":"payout","uuid":"u","status":"paid"}`), false)
+	require.NoError(t, err)
+	require.Equal(t, heleket.WebhookTypePayout, webhook.Type)
+	require.True(t, webhook.Status.IsSuccessful())
+	require.False(t, heleket.WebhookType("invoice").IsKnown())
+}
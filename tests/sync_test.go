@@ -0,0 +1,203 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/idanyas/heleket-go"
+	"github.com/idanyas/heleket-go/heleketfake"
+	"github.com/idanyas/heleket-go/heleketmock"
+	"github.com/idanyas/heleket-go/heleketsync"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncerMirrorsAndEmitsTransitions(t *testing.T) {
+	fake := newFake(t)
+	client := fake.Client()
+	var uuids []string
+	for i := range 2 {
+		invoice, err := client.CreateInvoice(&heleket.InvoiceRequest{Amount: "10", Currency: "USDT", OrderId: fmt.Sprintf("order-%d", i)})
+		require.NoError(t, err)
+		uuids = append(uuids, invoice.UUID)
+	}
+	payout, err := client.CreatePayout(&heleket.PayoutRequest{
+		Amount: "1", Currency: "USDT", Network: "tron", OrderId: "payout-1", Address: "TXLAQ63Xg1NAzckPwKHvzw7CSEmLMEqcdj",
+	})
+	require.NoError(t, err)
+
+	store := heleketsync.NewMemoryStore()
+	syncer := heleketsync.New(client, store)
+	var events []heleketsync.Event
+	syncer.Subscribe(func(e heleketsync.Event) { events = append(events, e) })
+
+	require.NoError(t, syncer.Sync(context.Background()))
+	require.Len(t, events, 3)
+	for _, e := range events {
+		require.True(t, e.Created())
+	}
+	require.Equal(t, heleketsync.KindPayout, events[2].Kind)
+	require.Equal(t, payout.UUID, events[2].Payout.UUID)
+
+	mark, err := store.Mark(context.Background(), heleketsync.KindPayment)
+	require.NoError(t, err)
+	require.False(t, mark.IsZero())
+
+	require.True(t, fake.UpdatePayment(uuids[1], func(p *heleket.Payment) {
+		p.PaymentStatus, p.Status, p.IsFinal = heleket.StatusPaid, heleket.StatusPaid, true
+	}))
+	events = nil
+	require.NoError(t, syncer.Sync(context.Background()))
+	require.Equal(t, []heleketsync.Event{{
+		Kind: heleketsync.KindPayment, UUID: uuids[1], From: heleket.StatusCheck, To: heleket.StatusPaid, Payment: events[0].Payment,
+	}}, events)
+
+	stored, err := store.GetPayment(context.Background(), uuids[1])
+	require.NoError(t, err)
+	require.True(t, stored.IsFinal)
+
+	// A new syncer over the same store picks up where the last one stopped.
+	events = nil
+	restarted := heleketsync.New(client, store)
+	restarted.Subscribe(func(e heleketsync.Event) { events = append(events, e) })
+	require.NoError(t, restarted.Sync(context.Background()))
+	require.Empty(t, events)
+
+	count := 0
+	for payment, err := range store.Payments(context.Background()) {
+		require.NoError(t, err)
+		require.Contains(t, uuids, payment.UUID)
+		count++
+	}
+	require.Equal(t, 2, count)
+}
+
+func TestSyncerUnsubscribe(t *testing.T) {
+	fake := newFake(t)
+	client := fake.Client()
+	_, err := client.CreateInvoice(&heleket.InvoiceRequest{Amount: "10", Currency: "USDT", OrderId: "order-1"})
+	require.NoError(t, err)
+
+	syncer := heleketsync.New(client, heleketsync.NewMemoryStore())
+	var first, second int
+	unsubscribe := syncer.Subscribe(func(heleketsync.Event) { first++ })
+	syncer.Subscribe(func(heleketsync.Event) { second++ })
+	unsubscribe()
+
+	require.NoError(t, syncer.Sync(context.Background()))
+	require.Equal(t, 0, first)
+	require.Equal(t, 1, second)
+}
+
+func TestSyncerDefaultsNonPositiveDurations(t *testing.T) {
+	fake := newFake(t)
+	syncer := heleketsync.New(fake.Client(), heleketsync.NewMemoryStore(),
+		heleketsync.WithInterval(0), heleketsync.WithLookback(-time.Minute))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.ErrorIs(t, syncer.Run(ctx), context.Canceled)
+}
+
+func TestSyncerKeepsMarkOnError(t *testing.T) {
+	historyErr := errors.New("history unavailable")
+	mock := &heleketmock.Client{
+		GetPaymentHistoryFunc: func(ctx context.Context, from, to time.Time, cursor string) (*heleket.PaymentHistoryResponse, error) {
+			return nil, historyErr
+		},
+		GetPayoutHistoryFunc: func(ctx context.Context, from, to time.Time, cursor string) (*heleket.PayoutHistoryResponse, error) {
+			return &heleket.PayoutHistoryResponse{}, nil
+		},
+	}
+
+	store := heleketsync.NewMemoryStore()
+	syncer := heleketsync.New(mock, store)
+	require.ErrorIs(t, syncer.Sync(context.Background()), historyErr)
+
+	mark, err := store.Mark(context.Background(), heleketsync.KindPayment)
+	require.NoError(t, err)
+	require.True(t, mark.IsZero())
+	mark, err = store.Mark(context.Background(), heleketsync.KindPayout)
+	require.NoError(t, err)
+	require.False(t, mark.IsZero())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var errs []error
+	syncer = heleketsync.New(mock, store,
+		heleketsync.WithInterval(time.Millisecond),
+		heleketsync.WithErrorHandler(func(err error) {
+			errs = append(errs, err)
+			if len(errs) == 3 {
+				cancel()
+			}
+		}))
+	require.ErrorIs(t, syncer.Run(ctx), context.Canceled)
+	require.Len(t, errs, 3)
+}
+
+func TestSyncerRangeEndsAtNextWholeSecond(t *testing.T) {
+	var gotFrom, gotTo time.Time
+	mock := &heleketmock.Client{
+		GetPaymentHistoryFunc: func(ctx context.Context, from, to time.Time, cursor string) (*heleket.PaymentHistoryResponse, error) {
+			gotFrom, gotTo = from, to
+			return &heleket.PaymentHistoryResponse{}, nil
+		},
+		GetPayoutHistoryFunc: func(ctx context.Context, from, to time.Time, cursor string) (*heleket.PayoutHistoryResponse, error) {
+			return &heleket.PayoutHistoryResponse{}, nil
+		},
+	}
+	now := time.Date(2025, 3, 1, 12, 0, 5, 200_000_000, time.UTC)
+	store := heleketsync.NewMemoryStore()
+	syncer := heleketsync.New(mock, store, heleketsync.WithClock(func() time.Time { return now }))
+	require.NoError(t, syncer.Sync(context.Background()))
+
+	end := time.Date(2025, 3, 1, 12, 0, 6, 0, time.UTC)
+	require.Equal(t, end, gotTo)
+	require.Equal(t, end.Add(-heleketsync.DefaultLookback), gotFrom)
+	mark, err := store.Mark(context.Background(), heleketsync.KindPayment)
+	require.NoError(t, err)
+	require.Equal(t, end, mark)
+}
+
+func TestSyncerPollsPendingRecordsOutsideLookback(t *testing.T) {
+	created := time.Now().Add(-72 * time.Hour)
+	fake := newFake(t, heleketfake.WithClock(func() time.Time { return created }))
+	client := fake.Client()
+	invoice, err := client.CreateInvoice(&heleket.InvoiceRequest{Amount: "10", Currency: "USDT", OrderId: "late"})
+	require.NoError(t, err)
+	paid, err := client.CreateInvoice(&heleket.InvoiceRequest{Amount: "10", Currency: "USDT", OrderId: "settled"})
+	require.NoError(t, err)
+	require.True(t, fake.UpdatePayment(paid.UUID, func(p *heleket.Payment) {
+		p.PaymentStatus, p.Status, p.IsFinal = heleket.StatusPaid, heleket.StatusPaid, true
+	}))
+
+	store := heleketsync.NewMemoryStore()
+	var events []heleketsync.Event
+	syncer := heleketsync.New(client, store, heleketsync.WithStart(created.Add(-time.Hour)))
+	syncer.Subscribe(func(e heleketsync.Event) { events = append(events, e) })
+	require.NoError(t, syncer.Sync(context.Background()))
+	require.Len(t, events, 2)
+
+	// Both invoices are now older than the lookback. The pending one is
+	// polled on its own and its late confirmation is still reported; the
+	// settled one is final, so its refund is not seen.
+	require.True(t, fake.UpdatePayment(invoice.UUID, func(p *heleket.Payment) {
+		p.PaymentStatus, p.Status, p.IsFinal = heleket.StatusPaid, heleket.StatusPaid, true
+	}))
+	require.True(t, fake.UpdatePayment(paid.UUID, func(p *heleket.Payment) {
+		p.PaymentStatus, p.Status = heleket.StatusRefundPaid, heleket.StatusRefundPaid
+	}))
+	events = nil
+	require.NoError(t, syncer.Sync(context.Background()))
+	require.Len(t, events, 1)
+	require.Equal(t, invoice.UUID, events[0].UUID)
+	require.Equal(t, heleket.StatusCheck, events[0].From)
+	require.Equal(t, heleket.StatusPaid, events[0].To)
+
+	events = nil
+	require.NoError(t, syncer.Sync(context.Background()))
+	require.Empty(t, events)
+}
@@ -0,0 +1,65 @@
+package tests
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"testing"
+
+	"github.com/idanyas/heleket-go"
+
+	"github.com/stretchr/testify/require"
+)
+
+// signWebhook appends a "sign" field computed the way Heleket does.
+func signWebhook(body, apiKey string) []byte {
+	hash := md5.Sum([]byte(base64.StdEncoding.EncodeToString([]byte(body)) + apiKey))
+	return []byte(body[:len(body)-1] + `,"sign":"` + hex.EncodeToString(hash[:]) + `"}`)
+}
+
+func TestTracerRecordsCalls(t *testing.T) {
+	tracer := &heleket.RecordingTracer{}
+	client := newStubHeleket(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"state":1,"message":"Payment not found"}`))
+	}, heleket.WithTracer(tracer))
+
+	_, err := client.GetPaymentInfo(&heleket.PaymentInfoRequest{OrderId: "order-7"})
+	require.ErrorIs(t, err, heleket.ErrNotFound)
+
+	spans := tracer.Spans()
+	require.Len(t, spans, 1)
+	span := spans[0]
+	require.Equal(t, "heleket /payment/info", span.Name)
+	require.Equal(t, "/payment/info", span.Attributes[heleket.AttrEndpoint])
+	require.Equal(t, "merchant", span.Attributes[heleket.AttrMerchant])
+	require.Equal(t, "order-7", span.Attributes[heleket.AttrOrderId])
+	require.Equal(t, int8(1), span.Attributes[heleket.AttrState])
+	require.Equal(t, http.StatusNotFound, span.Attributes[heleket.AttrStatusCode])
+	require.Len(t, span.Errors, 1)
+	require.False(t, span.EndTime.IsZero())
+}
+
+func TestTracerRecordsWebhooks(t *testing.T) {
+	tracer := &heleket.RecordingTracer{}
+	client := heleket.New(nil, "merchant", "payment-key", "payout-key", heleket.WithTracer(tracer))
+
+	body := signWebhook(`{"type":"payment","uuid":"u1","order_id":"o1","status":"paid"}`, "payment-key")
+	webhook, err := client.ParseWebhook(body, true)
+	require.NoError(t, err)
+	require.Equal(t, heleket.StatusPaid, webhook.Status)
+
+	spans := tracer.Spans()
+	require.Len(t, spans, 2)
+	require.Equal(t, "heleket.VerifySign", spans[0].Name)
+	require.Equal(t, "heleket.ParseWebhook", spans[1].Name)
+	require.Same(t, spans[1], spans[0].Parent)
+	require.Equal(t, "o1", spans[1].Attributes[heleket.AttrOrderId])
+	require.Equal(t, "paid", spans[1].Attributes[heleket.AttrStatus])
+
+	tracer.Reset()
+	_, err = client.ParseWebhook(signWebhook(`{"type":"payment","uuid":"u1"}`, "wrong-key"), true)
+	require.Error(t, err)
+	require.Len(t, tracer.Spans()[0].Errors, 1)
+}
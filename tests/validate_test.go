@@ -0,0 +1,145 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/idanyas/heleket-go"
+	"github.com/idanyas/heleket-go/heleketfake"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvoiceRequestValidate(t *testing.T) {
+	valid := &heleket.InvoiceRequest{
+		Amount:   "10.50",
+		Currency: "USD",
+		OrderId:  "order_1-a",
+		InvoiceRequestOptions: &heleket.InvoiceRequestOptions{
+			Network:                "tron",
+			ToCurrency:             "USDT",
+			UrlCallback:            "https://example.com/callback",
+			Lifetime:               heleket.MinInvoiceLifetime,
+			AccuracyPaymentPercent: heleket.MaxAccuracyPaymentPercent,
+			Subtract:               100,
+			DiscountPercent:        -10,
+			PayerEmail:             "payer@example.com",
+		},
+	}
+	require.NoError(t, valid.Validate())
+
+	invalid := &heleket.InvoiceRequest{
+		Amount:   "1,5",
+		Currency: "US Dollar",
+		OrderId:  "order #1",
+		InvoiceRequestOptions: &heleket.InvoiceRequestOptions{
+			Network:                "trc20",
+			UrlReturn:              "example.com/return",
+			Lifetime:               100,
+			AccuracyPaymentPercent: 6,
+			Subtract:               101,
+			DiscountPercent:        -100,
+			PayerEmail:             "payer",
+		},
+	}
+	err := invalid.Validate()
+	require.ErrorIs(t, err, heleket.ErrValidation)
+
+	var validationErr *heleket.ValidationError
+	require.True(t, errors.As(err, &validationErr))
+	for _, field := range []string{
+		"amount", "currency", "order_id", "network", "url_return", "lifetime",
+		"accuracy_payment_percent", "subtract", "discount_percent", "payer_email",
+	} {
+		require.Contains(t, validationErr.Fields, field)
+	}
+	require.Contains(t, validationErr.Fields["network"][0], `did you mean "tron"`)
+
+	require.Error(t, (&heleket.InvoiceRequest{Amount: "0", Currency: "USD", OrderId: "o"}).Validate())
+	require.Error(t, (&heleket.InvoiceRequest{Amount: "5", Currency: "USD", OrderId: strings.Repeat("a", 129)}).Validate())
+}
+
+func TestNetworkValidationDefersToAPI(t *testing.T) {
+	// Without refreshed services, networks and pairs the built-in registry
+	// does not list are left to the API.
+	require.NoError(t, (&heleket.PayoutRequest{Amount: "5", Currency: "USDT", Network: "base", OrderId: "p", Address: "T"}).Validate())
+	require.NoError(t, (&heleket.InvoiceRequest{Amount: "5", Currency: "ETH", OrderId: "o",
+		InvoiceRequestOptions: &heleket.InvoiceRequestOptions{Network: "bsc"}}).Validate())
+
+	fake := newFake(t,
+		heleketfake.WithPaymentServices([]*heleket.PaymentService{
+			{Currency: "ETH", Network: "eth", IsAvailable: true},
+			{Currency: "ETH", Network: "bsc", IsAvailable: true},
+		}),
+		heleketfake.WithPayoutServices([]*heleket.PayoutService{
+			{Currency: "USDT", Network: "tron", IsAvailable: true},
+			{Currency: "USDT", Network: "bsc", IsAvailable: false},
+		}),
+	)
+	registry := heleket.NewRegistry()
+	require.NoError(t, registry.Refresh(context.Background(), fake.Client()))
+	client := fake.Client(heleket.WithRegistry(registry))
+
+	_, err := client.CreateInvoice(&heleket.InvoiceRequest{Amount: "5", Currency: "ETH", OrderId: "o",
+		InvoiceRequestOptions: &heleket.InvoiceRequestOptions{Network: "bsc"}})
+	require.NoError(t, err)
+
+	_, err = client.CreatePayout(&heleket.PayoutRequest{Amount: "5", Currency: "USDT", Network: "base", OrderId: "p", Address: "T"})
+	require.ErrorIs(t, err, heleket.ErrValidation)
+	require.EqualError(t, err, `heleket: invalid request: network: USDT is available on bsc, tron, not "base"`)
+
+	// Currencies the services lists leave out are passed through for the
+	// API to decide.
+	_, err = client.CreatePayout(&heleket.PayoutRequest{Amount: "0.01", Currency: "LTC", Network: "ltc", OrderId: "p", Address: "LKKHMBjCU89fyFNgSRprDoD8Jb25N8uWvd"})
+	var apiErr *heleket.APIError
+	require.True(t, errors.As(err, &apiErr), err)
+}
+
+func TestOtherRequestsValidate(t *testing.T) {
+	require.NoError(t, (&heleket.PayoutRequest{Amount: "5", Currency: "USDT", Network: "tron", OrderId: "p", Address: "T"}).Validate())
+	err := (&heleket.PayoutRequest{Amount: "-5", Currency: "USDT", OrderId: "p",
+		PayoutRequestOptions: &heleket.PayoutRequestOptions{Priority: "urgent"}}).Validate()
+	var validationErr *heleket.ValidationError
+	require.True(t, errors.As(err, &validationErr))
+	require.ElementsMatch(t, []string{"amount", "address", "network", "priority"}, slices.Collect(maps.Keys(validationErr.Fields)))
+
+	require.NoError(t, (&heleket.StaticWalletRequest{Currency: "USDT", Network: "bsc", OrderId: "w"}).Validate())
+	require.ErrorIs(t, (&heleket.StaticWalletRequest{Currency: "USDT", Network: "erc20", OrderId: "w"}).Validate(), heleket.ErrValidation)
+
+	require.NoError(t, (&heleket.RefundRequest{OrderId: "o", Address: "T"}).Validate())
+	require.ErrorIs(t, (&heleket.RefundRequest{Address: "T", Amount: "abc"}).Validate(), heleket.ErrValidation)
+
+	require.NoError(t, (&heleket.SetDiscountRequest{Currency: "USDT", Network: "tron", DiscountPercent: -99}).Validate())
+	require.ErrorIs(t, (&heleket.SetDiscountRequest{Currency: "USDT", Network: "tron", DiscountPercent: 101}).Validate(), heleket.ErrValidation)
+}
+
+func TestValidationRunsBeforeSending(t *testing.T) {
+	var calls atomic.Int32
+	client := newStubHeleket(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Write([]byte(`{"state":0,"result":{}}`))
+	})
+
+	_, err := client.CreateInvoice(&heleket.InvoiceRequest{Amount: "10", Currency: "USD", OrderId: "o",
+		InvoiceRequestOptions: &heleket.InvoiceRequestOptions{Lifetime: 60}})
+	require.ErrorIs(t, err, heleket.ErrValidation)
+	require.EqualError(t, err, "heleket: invalid request: lifetime: must be between 300 and 43200")
+
+	_, err = client.CreateStaticWallet(&heleket.StaticWalletRequest{Currency: "USDT", OrderId: "w"})
+	require.ErrorIs(t, err, heleket.ErrValidation)
+
+	_, err = client.SetDiscount(&heleket.SetDiscountRequest{Currency: "USDT", Network: "tron", DiscountPercent: 120})
+	require.ErrorIs(t, err, heleket.ErrValidation)
+
+	_, err = client.Refund(&heleket.RefundRequest{Address: "TXLAQ63Xg1NAzckPwKHvzw7CSEmLMEqcdj"})
+	var validationErr *heleket.ValidationError
+	require.True(t, errors.As(err, &validationErr))
+	require.Equal(t, []string{"is required when order_id is not set"}, validationErr.Fields["uuid"])
+	require.Zero(t, calls.Load())
+}
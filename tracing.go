@@ -0,0 +1,133 @@
+package heleket
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Attribute is a key/value pair attached to a span.
+type Attribute struct {
+	Key   string
+	Value any
+}
+
+// Tracer starts spans. It is a minimal interface that adapters for tracing
+// libraries such as OpenTelemetry can implement.
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is a single traced operation.
+type Span interface {
+	SetAttributes(attrs ...Attribute)
+	RecordError(err error)
+	End()
+}
+
+// WithTracer traces every call, webhook parse and signature check with tracer.
+func WithTracer(tracer Tracer) Option {
+	return func(c *Heleket) {
+		c.tracer = tracer
+	}
+}
+
+// Span attribute keys set by the client.
+const (
+	AttrEndpoint    = "heleket.endpoint"
+	AttrMethod      = "heleket.method"
+	AttrKey         = "heleket.key"
+	AttrMerchant    = "heleket.merchant"
+	AttrOrderId     = "heleket.order_id"
+	AttrUUID        = "heleket.uuid"
+	AttrState       = "heleket.state"
+	AttrStatusCode  = "heleket.status_code"
+	AttrAttempts    = "heleket.attempts"
+	AttrWebhookType = "heleket.webhook.type"
+	AttrStatus      = "heleket.status"
+)
+
+// NoopTracer is a Tracer that records nothing. It is the default.
+type NoopTracer struct{}
+
+func (NoopTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttributes(...Attribute) {}
+func (noopSpan) RecordError(error)          {}
+func (noopSpan) End()                       {}
+
+// RecordingTracer keeps finished spans in memory, for use in tests.
+type RecordingTracer struct {
+	mu    sync.Mutex
+	spans []*RecordedSpan
+}
+
+// RecordedSpan is a span captured by a RecordingTracer.
+type RecordedSpan struct {
+	Name       string
+	Parent     *RecordedSpan
+	Attributes map[string]any
+	Errors     []error
+	StartTime  time.Time
+	EndTime    time.Time
+
+	tracer *RecordingTracer
+	mu     sync.Mutex
+}
+
+type recordedSpanKey struct{}
+
+func (t *RecordingTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	span := &RecordedSpan{
+		Name:       name,
+		Attributes: make(map[string]any),
+		StartTime:  time.Now(),
+		tracer:     t,
+	}
+	span.Parent, _ = ctx.Value(recordedSpanKey{}).(*RecordedSpan)
+	return context.WithValue(ctx, recordedSpanKey{}, span), (*recordingSpan)(span)
+}
+
+// Spans returns the spans that have ended, in the order they ended.
+func (t *RecordingTracer) Spans() []*RecordedSpan {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]*RecordedSpan(nil), t.spans...)
+}
+
+// Reset discards all recorded spans.
+func (t *RecordingTracer) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.spans = nil
+}
+
+type recordingSpan RecordedSpan
+
+func (s *recordingSpan) SetAttributes(attrs ...Attribute) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, attr := range attrs {
+		s.Attributes[attr.Key] = attr.Value
+	}
+}
+
+func (s *recordingSpan) RecordError(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Errors = append(s.Errors, err)
+}
+
+func (s *recordingSpan) End() {
+	s.mu.Lock()
+	s.EndTime = time.Now()
+	s.mu.Unlock()
+
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+	s.tracer.spans = append(s.tracer.spans, (*RecordedSpan)(s))
+}
@@ -0,0 +1,276 @@
+package heleket
+
+import (
+	"fmt"
+	"maps"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// ValidationError is returned when a request fails client-side validation
+// before it is sent. Fields maps JSON field names to problems, in the same
+// shape as APIError.Errors. It matches ErrValidation with errors.Is.
+type ValidationError struct {
+	Fields map[string][]string
+}
+
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	b.WriteString("heleket: invalid request")
+	for i, field := range slices.Sorted(maps.Keys(e.Fields)) {
+		if i == 0 {
+			b.WriteString(": ")
+		} else {
+			b.WriteString("; ")
+		}
+		fmt.Fprintf(&b, "%s: %s", field, strings.Join(e.Fields[field], ", "))
+	}
+	return b.String()
+}
+
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// Request field limits documented by Heleket.
+const (
+	MinInvoiceLifetime        = 300
+	MaxInvoiceLifetime        = 43200
+	MaxAccuracyPaymentPercent = 5
+	MaxSubtractPercent        = 100
+	MinDiscountPercent        = -99
+	MaxDiscountPercent        = 100
+	maxOrderIdLength          = 128
+	maxAdditionalDataLength   = 255
+	maxURLLength              = 255
+)
+
+var (
+	orderIdPattern  = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+	currencyPattern = regexp.MustCompile(`^[A-Za-z0-9]{2,10}$`)
+)
+
+var payoutPriorities = []string{"recommended", "economy", "high", "highest"}
+
+// fieldErrors collects validation problems by field.
+type fieldErrors map[string][]string
+
+func (f fieldErrors) add(field, format string, args ...any) {
+	f[field] = append(f[field], fmt.Sprintf(format, args...))
+}
+
+func (f fieldErrors) err() error {
+	if len(f) == 0 {
+		return nil
+	}
+	return &ValidationError{Fields: f}
+}
+
+func (f fieldErrors) amount(field, value string, required bool) {
+	if value == "" {
+		if required {
+			f.add(field, "is required")
+		}
+		return
+	}
+	amount, err := ParseAmount(value)
+	if err != nil || amount.Sign() <= 0 {
+		f.add(field, "must be a positive decimal number")
+	}
+}
+
+func (f fieldErrors) orderId(value string, required bool) {
+	switch {
+	case value == "":
+		if required {
+			f.add("order_id", "is required")
+		}
+	case len(value) > maxOrderIdLength:
+		f.add("order_id", "must be at most %d characters", maxOrderIdLength)
+	case !orderIdPattern.MatchString(value):
+		f.add("order_id", "may only contain letters, numbers, dashes and underscores")
+	}
+}
+
+func (f fieldErrors) currency(field, value string, required bool) {
+	switch {
+	case value == "":
+		if required {
+			f.add(field, "is required")
+		}
+	case !currencyPattern.MatchString(value):
+		f.add(field, "must be a currency code such as USDT")
+	}
+}
+
+// network rejects aliases of registered networks, such as "trc20" for
+// "tron", and pairs the last Refresh for keyKind listed the currency
+// without. Networks and pairs the registry does not know are left to the
+// API, which may support more than the built-in list.
+func (f fieldErrors) network(r *Registry, keyKind KeyKind, field, currency, network string, required bool) {
+	if network == "" {
+		if required {
+			f.add(field, "is required")
+		}
+		return
+	}
+	if _, ok := r.Network(network); !ok {
+		if alias, ok := networkAliases[strings.ToLower(network)]; ok {
+			if _, ok := r.Network(alias); ok {
+				f.add(field, "unknown network %q, did you mean %q?", network, alias)
+				return
+			}
+		}
+	}
+	if info, ok := r.Currency(currency); ok && info.Fiat {
+		return
+	}
+	if listed := r.listedNetworks(keyKind, currency); len(listed) > 0 && !slices.Contains(listed, strings.ToLower(network)) {
+		f.add(field, "%s is available on %s, not %q", strings.ToUpper(currency), strings.Join(listed, ", "), network)
+	}
+}
+
+func (f fieldErrors) url(field, value string) {
+	if value == "" {
+		return
+	}
+	u, err := url.Parse(value)
+	switch {
+	case len(value) > maxURLLength:
+		f.add(field, "must be at most %d characters", maxURLLength)
+	case err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "":
+		f.add(field, "must be an absolute http or https URL")
+	}
+}
+
+func (f fieldErrors) between(field string, value, lo, hi int) {
+	if value < lo || value > hi {
+		f.add(field, "must be between %d and %d", lo, hi)
+	}
+}
+
+// Validate checks the request against the limits Heleket documents.
+// CreateInvoice calls it before sending the request.
+func (r *InvoiceRequest) Validate() error {
+	return r.validate(DefaultRegistry)
+}
+
+func (r *InvoiceRequest) validate(registry *Registry) error {
+	f := fieldErrors{}
+	f.amount("amount", r.Amount, true)
+	f.currency("currency", r.Currency, true)
+	f.orderId(r.OrderId, true)
+
+	if o := r.InvoiceRequestOptions; o != nil {
+		if o.Network != "" {
+			currency := r.Currency
+			if o.ToCurrency != "" {
+				currency = o.ToCurrency
+			}
+			f.network(registry, PaymentKey, "network", currency, o.Network, false)
+		}
+		f.url("url_return", o.UrlReturn)
+		f.url("url_success", o.UrlSuccess)
+		f.url("url_callback", o.UrlCallback)
+		if o.Lifetime != 0 {
+			f.between("lifetime", int(o.Lifetime), MinInvoiceLifetime, MaxInvoiceLifetime)
+		}
+		f.currency("to_currency", o.ToCurrency, false)
+		f.between("subtract", int(o.Subtract), 0, MaxSubtractPercent)
+		f.between("accuracy_payment_percent", int(o.AccuracyPaymentPercent), 0, MaxAccuracyPaymentPercent)
+		f.between("discount_percent", int(o.DiscountPercent), MinDiscountPercent, MaxDiscountPercent)
+		if len(o.AdditionalData) > maxAdditionalDataLength {
+			f.add("additional_data", "must be at most %d characters", maxAdditionalDataLength)
+		}
+		if o.PayerEmail != "" {
+			if _, err := mail.ParseAddress(o.PayerEmail); err != nil {
+				f.add("payer_email", "must be a valid email address")
+			}
+		}
+		for i, c := range o.Currencies {
+			f.currency(fmt.Sprintf("currencies.%d.currency", i), c.Currency, true)
+			f.network(registry, PaymentKey, fmt.Sprintf("currencies.%d.network", i), c.Currency, c.Network, false)
+		}
+		for i, c := range o.ExceptCurrencies {
+			f.currency(fmt.Sprintf("except_currencies.%d.currency", i), c.Currency, true)
+			f.network(registry, PaymentKey, fmt.Sprintf("except_currencies.%d.network", i), c.Currency, c.Network, false)
+		}
+	}
+	return f.err()
+}
+
+// Validate checks the request against the limits Heleket documents.
+// CreatePayout calls it before sending the request.
+func (r *PayoutRequest) Validate() error {
+	return r.validate(DefaultRegistry)
+}
+
+func (r *PayoutRequest) validate(registry *Registry) error {
+	f := fieldErrors{}
+	f.amount("amount", r.Amount, true)
+	f.currency("currency", r.Currency, true)
+	f.orderId(r.OrderId, true)
+	if r.Address == "" {
+		f.add("address", "is required")
+	}
+	f.network(registry, PayoutKey, "network", r.Currency, r.Network, true)
+
+	if o := r.PayoutRequestOptions; o != nil {
+		f.url("url_callback", o.UrlCallback)
+		f.currency("to_currency", o.ToCurrency, false)
+		f.currency("from_currency", o.FromCurrency, false)
+		if o.Priority != "" && !slices.Contains(payoutPriorities, o.Priority) {
+			f.add("priority", "must be one of %s", strings.Join(payoutPriorities, ", "))
+		}
+	}
+	return f.err()
+}
+
+// Validate checks the request against the limits Heleket documents.
+// CreateStaticWallet calls it before sending the request.
+func (r *StaticWalletRequest) Validate() error {
+	return r.validate(DefaultRegistry)
+}
+
+func (r *StaticWalletRequest) validate(registry *Registry) error {
+	f := fieldErrors{}
+	f.currency("currency", r.Currency, true)
+	f.network(registry, PaymentKey, "network", r.Currency, r.Network, true)
+	f.orderId(r.OrderId, true)
+	if o := r.StaticWalletRequestOptions; o != nil {
+		f.url("url_callback", o.UrlCallback)
+	}
+	return f.err()
+}
+
+// Validate checks the request against the limits Heleket documents.
+// Refund calls it before sending the request.
+func (r *RefundRequest) Validate() error {
+	f := fieldErrors{}
+	if r.PaymentUUID == "" && r.OrderId == "" {
+		f.add("uuid", "is required when order_id is not set")
+	}
+	f.orderId(r.OrderId, false)
+	if r.Address == "" {
+		f.add("address", "is required")
+	}
+	f.amount("amount", r.Amount, false)
+	return f.err()
+}
+
+// Validate checks the request against the limits Heleket documents.
+// SetDiscount calls it before sending the request.
+func (r *SetDiscountRequest) Validate() error {
+	return r.validate(DefaultRegistry)
+}
+
+func (r *SetDiscountRequest) validate(registry *Registry) error {
+	f := fieldErrors{}
+	f.currency("currency", r.Currency, true)
+	f.network(registry, PaymentKey, "network", r.Currency, r.Network, true)
+	f.between("discount_percent", int(r.DiscountPercent), MinDiscountPercent, MaxDiscountPercent)
+	return f.err()
+}
@@ -1,6 +1,7 @@
 package heleket
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 )
@@ -20,7 +21,7 @@ type WebhookConvert struct {
 }
 
 type Webhook struct {
-	Type              string          `json:"type"`
+	Type              WebhookType     `json:"type"`
 	UUID              string          `json:"uuid"`
 	OrderId           string          `json:"order_id"`
 	Amount            string          `json:"amount"`
@@ -29,7 +30,7 @@ type Webhook struct {
 	MerchantAmount    string          `json:"merchant_amount"`
 	Commission        string          `json:"commission"`
 	IsFinal           bool            `json:"is_final"`
-	Status            string          `json:"status"`
+	Status            Status          `json:"status"`
 	From              string          `json:"from"`
 	WalletAddressUUID string          `json:"wallet_address_uuid"`
 	Network           string          `json:"network"`
@@ -57,7 +58,7 @@ type TestWebhookRequest struct {
 	Network     string `json:"network"`
 	UUID        string `json:"uuid,omitempty"`
 	OrderId     string `json:"order_id,omitempty"`
-	Status      string `json:"status"`
+	Status      Status `json:"status"`
 }
 
 type TestWebhookResponse struct {
@@ -66,25 +67,45 @@ type TestWebhookResponse struct {
 }
 
 func (c *Heleket) ParseWebhook(reqBody []byte, verifySign bool) (*Webhook, error) {
+	return c.ParseWebhookContext(context.Background(), reqBody, verifySign)
+}
+
+func (c *Heleket) ParseWebhookContext(ctx context.Context, reqBody []byte, verifySign bool) (_ *Webhook, err error) {
+	ctx, span := c.tracer.Start(ctx, "heleket.ParseWebhook")
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+	span.SetAttributes(Attribute{AttrMerchant, c.merchant})
+
 	var apiKey string
 	response := &Webhook{}
 
-	err := json.Unmarshal(reqBody, response)
+	err = json.Unmarshal(reqBody, response)
 	if err != nil {
 		return nil, err
 	}
 
+	span.SetAttributes(
+		Attribute{AttrWebhookType, string(response.Type)},
+		Attribute{AttrUUID, response.UUID},
+		Attribute{AttrOrderId, response.OrderId},
+		Attribute{AttrStatus, string(response.Status)},
+	)
+
 	switch response.Type {
-	case "payment", "wallet":
+	case WebhookTypePayment, WebhookTypeWallet:
 		apiKey = c.paymentApiKey
-	case "payout":
+	case WebhookTypePayout:
 		apiKey = c.payoutApiKey
 	default:
 		return nil, errors.New("unknown webhook type")
 	}
 
 	if verifySign {
-		err = c.VerifySign(apiKey, reqBody)
+		err = c.VerifySignContext(ctx, apiKey, reqBody)
 		if err != nil {
 			return nil, err
 		}
@@ -94,19 +115,16 @@ func (c *Heleket) ParseWebhook(reqBody []byte, verifySign bool) (*Webhook, error
 }
 
 func (c *Heleket) ResendWebhook(resendRequest *ResendWebhookRequest) (bool, error) {
+	return c.ResendWebhookContext(context.Background(), resendRequest)
+}
+
+func (c *Heleket) ResendWebhookContext(ctx context.Context, resendRequest *ResendWebhookRequest) (bool, error) {
 	if resendRequest.PaymentUUID == "" && resendRequest.OrderId == "" {
 		return false, errors.New("you should pass one of required values [PaymentUUID, OrderId]")
 	}
 
-	res, err := c.fetch("POST", resendWebhookEndpoint, resendRequest, c.paymentApiKey)
-	if err != nil {
-		return false, err
-	}
-
-	defer res.Body.Close()
-
 	response := &resendWebhookRawResponse{}
-	if err = json.NewDecoder(res.Body).Decode(response); err != nil {
+	if err := c.fetch(ctx, "POST", resendWebhookEndpoint, resendRequest, PaymentKey, response); err != nil {
 		return false, err
 	}
 
@@ -114,15 +132,12 @@ func (c *Heleket) ResendWebhook(resendRequest *ResendWebhookRequest) (bool, erro
 }
 
 func (c *Heleket) TestPaymentWebhook(testRequest *TestWebhookRequest) (*TestWebhookResponse, error) {
-	res, err := c.fetch("POST", testPaymentWebhookEndpoint, testRequest, c.paymentApiKey)
-	if err != nil {
-		return nil, err
-	}
-
-	defer res.Body.Close()
+	return c.TestPaymentWebhookContext(context.Background(), testRequest)
+}
 
+func (c *Heleket) TestPaymentWebhookContext(ctx context.Context, testRequest *TestWebhookRequest) (*TestWebhookResponse, error) {
 	response := &TestWebhookResponse{}
-	if err = json.NewDecoder(res.Body).Decode(response); err != nil {
+	if err := c.fetch(ctx, "POST", testPaymentWebhookEndpoint, testRequest, PaymentKey, response); err != nil {
 		return nil, err
 	}
 
@@ -130,15 +145,12 @@ func (c *Heleket) TestPaymentWebhook(testRequest *TestWebhookRequest) (*TestWebh
 }
 
 func (c *Heleket) TestPayoutWebhook(testRequest *TestWebhookRequest) (*TestWebhookResponse, error) {
-	res, err := c.fetch("POST", testPayoutWebhookEndpoint, testRequest, c.payoutApiKey)
-	if err != nil {
-		return nil, err
-	}
-
-	defer res.Body.Close()
+	return c.TestPayoutWebhookContext(context.Background(), testRequest)
+}
 
+func (c *Heleket) TestPayoutWebhookContext(ctx context.Context, testRequest *TestWebhookRequest) (*TestWebhookResponse, error) {
 	response := &TestWebhookResponse{}
-	if err = json.NewDecoder(res.Body).Decode(response); err != nil {
+	if err := c.fetch(ctx, "POST", testPayoutWebhookEndpoint, testRequest, PayoutKey, response); err != nil {
 		return nil, err
 	}
 
@@ -146,15 +158,12 @@ func (c *Heleket) TestPayoutWebhook(testRequest *TestWebhookRequest) (*TestWebho
 }
 
 func (c *Heleket) TestWalletWebhook(testRequest *TestWebhookRequest) (*TestWebhookResponse, error) {
-	res, err := c.fetch("POST", testWalletWebhookEndpoint, testRequest, c.paymentApiKey)
-	if err != nil {
-		return nil, err
-	}
-
-	defer res.Body.Close()
+	return c.TestWalletWebhookContext(context.Background(), testRequest)
+}
 
+func (c *Heleket) TestWalletWebhookContext(ctx context.Context, testRequest *TestWebhookRequest) (*TestWebhookResponse, error) {
 	response := &TestWebhookResponse{}
-	if err = json.NewDecoder(res.Body).Decode(response); err != nil {
+	if err := c.fetch(ctx, "POST", testWalletWebhookEndpoint, testRequest, PaymentKey, response); err != nil {
 		return nil, err
 	}
 